@@ -0,0 +1,77 @@
+package ast
+
+import "github.com/sam8helloworld/json-go/value"
+
+// Position は入力での位置
+// Offsetはrune単位のインデックス、LineとColumnは1から数え、Columnはrune単位
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Range はノードの入力での範囲
+// Endは末尾の次の文字の位置
+type Range struct {
+	Start Position
+	End   Position
+}
+
+func (r Range) Span() Range {
+	return r
+}
+
+// Node はObject、Array、Scalarのどれか
+type Node interface {
+	Span() Range
+}
+
+type Object struct {
+	Range
+	Members []*Member
+}
+
+// Member はobjectのキーと値の組で、範囲はキーの先頭から値の末尾まで
+type Member struct {
+	Range
+	Key   *Key
+	Value Node
+}
+
+// Key はobjectのキーで、範囲はクォートを含む
+type Key struct {
+	Range
+	Name string
+}
+
+type Array struct {
+	Range
+	Elements []Node
+}
+
+// Scalar は文字列、数値、bool、nullの値
+type Scalar struct {
+	Range
+	Value interface{}
+}
+
+// ToValue は位置を除いた値にする
+func ToValue(n Node) interface{} {
+	switch n := n.(type) {
+	case *Object:
+		o := value.Object{}
+		for _, m := range n.Members {
+			o[m.Key.Name] = ToValue(m.Value)
+		}
+		return o
+	case *Array:
+		a := make(value.Array, 0, len(n.Elements))
+		for _, e := range n.Elements {
+			a = append(a, ToValue(e))
+		}
+		return a
+	case *Scalar:
+		return n.Value
+	}
+	return nil
+}
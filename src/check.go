@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/token"
+)
+
+// runCheck はJSONファイルの構文エラーを1回で全て報告する
+// エラーがなければ0、あれば1、ファイルが読めないなどの場合は2を返す
+//
+//	json-go check file...
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: json-go check file...")
+		return 2
+	}
+
+	code := 0
+	for _, path := range fs.Args() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		for _, d := range check(string(b)) {
+			fmt.Printf("%s:%v\n", path, d)
+			code = 1
+		}
+	}
+	return code
+}
+
+// check はinputの字句解析とパースのエラーを入力の順に返す
+// 字句解析のエラーになったトークンはnullに置き換えて、続きをパースする
+func check(input string) parser.Diagnostics {
+	l := lexer.NewLexer(input)
+	tokens, spans := []token.Token{}, []token.Span{}
+	diags := parser.Diagnostics{}
+	lexed := map[int]bool{}
+	for {
+		t, err := l.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			diags = append(diags, parser.NewDiagnostic(l.Input, l.Span().Start, err))
+			// エンコーディングのエラーは入力全体が読めないので、それ以上報告しない
+			if errors.Is(err, lexer.ErrEncoding) {
+				return diags
+			}
+			lexed[l.Span().Start] = true
+			t = token.NullToken{}
+		}
+		tokens = append(tokens, t)
+		spans = append(spans, l.Span())
+	}
+	_, err := parser.NewParser(tokens, parser.WithSource(l.Input, spans)).ExecuteRecover()
+	var parsed parser.Diagnostics
+	if errors.As(err, &parsed) {
+		for _, d := range parsed {
+			// 字句解析のエラーと同じ位置のものは、nullに置き換えたことによるエラーなので捨てる
+			if !lexed[d.Position.Offset] {
+				diags = append(diags, d)
+			}
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Position.Offset < diags[j].Position.Offset
+	})
+	return diags
+}
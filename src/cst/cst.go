@@ -0,0 +1,327 @@
+package cst
+
+import (
+	"strings"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/token"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+type Kind int
+
+const (
+	Scalar Kind = iota
+	Object
+	Array
+)
+
+// Document は空白やコメントも含めて入力をそのまま持つ構文木 (CST)
+// Stringは編集していない部分を入力と同じテキストで出力する
+type Document struct {
+	// bom は入力の先頭にBOMがあったこと
+	bom bool
+	// leading は値の前、trailing は値の後ろの空白とコメント
+	leading  string
+	root     *Node
+	trailing string
+}
+
+// Node は1つの値
+// スカラーは入力の綴りのまま、objectと配列は要素とその間の空白とコメントを持つ
+type Node struct {
+	kind Kind
+	// text はスカラーの入力でのテキスト
+	text  string
+	token token.Token
+
+	items []*Item
+	// trailingComma は最後の要素の後ろにカンマがあること
+	trailingComma bool
+	// end は閉じ括弧の前の空白とコメント
+	// 末尾のカンマがあればその後ろ、空のobjectと配列なら括弧の間のもの
+	end string
+}
+
+// Item はobjectのメンバーか配列の要素
+// 配列の要素の場合はkeyを使わない
+type Item struct {
+	// leading は開き括弧かカンマの後ろ、trailing は値の後ろでカンマか閉じ括弧の前の空白とコメント
+	leading string
+	key     string
+	// rawKey はクォートを含むキーの入力でのテキスト
+	rawKey      string
+	beforeColon string
+	afterColon  string
+	value       *Node
+	trailing    string
+}
+
+func (n *Node) Kind() Kind {
+	return n.kind
+}
+
+// Value は空白とコメントを除いた値にする
+func (n *Node) Value() interface{} {
+	switch n.kind {
+	case Object:
+		o := value.Object{}
+		for _, item := range n.items {
+			o[item.key] = item.value.Value()
+		}
+		return o
+	case Array:
+		a := make(value.Array, 0, len(n.items))
+		for _, item := range n.items {
+			a = append(a, item.value.Value())
+		}
+		return a
+	}
+	// パースするときに検査しているのでエラーにはならない
+	v, _ := parser.NewParser([]token.Token{n.token}).Execute()
+	return v
+}
+
+func (n *Node) write(b *strings.Builder) {
+	switch n.kind {
+	case Scalar:
+		b.WriteString(n.text)
+		return
+	case Object:
+		b.WriteByte('{')
+	case Array:
+		b.WriteByte('[')
+	}
+	for i, item := range n.items {
+		b.WriteString(item.leading)
+		if n.kind == Object {
+			b.WriteString(item.rawKey)
+			b.WriteString(item.beforeColon)
+			b.WriteByte(':')
+			b.WriteString(item.afterColon)
+		}
+		item.value.write(b)
+		b.WriteString(item.trailing)
+		if i < len(n.items)-1 || n.trailingComma {
+			b.WriteByte(',')
+		}
+	}
+	b.WriteString(n.end)
+	if n.kind == Object {
+		b.WriteByte('}')
+	} else {
+		b.WriteByte(']')
+	}
+}
+
+// Root は文書全体の値を返す
+func (d *Document) Root() *Node {
+	return d.root
+}
+
+// Value は空白とコメントを除いた文書全体の値を返す
+func (d *Document) Value() interface{} {
+	return d.root.Value()
+}
+
+func (d *Document) String() string {
+	var b strings.Builder
+	if d.bom {
+		b.WriteRune('\uFEFF')
+	}
+	b.WriteString(d.leading)
+	d.root.write(&b)
+	b.WriteString(d.trailing)
+	return b.String()
+}
+
+// Parser は入力を空白とコメントを捨てずにDocumentにする
+// 設定ファイルを編集するためのもので、JSONCのコメントと末尾のカンマも受け付ける
+type Parser struct {
+	input string
+
+	source []rune
+	tokens []token.Token
+	spans  []token.Span
+	index  int
+	// pos は読み終えたトークンの入力での末尾
+	pos int
+}
+
+func NewParser(input string) *Parser {
+	return &Parser{
+		input: input,
+	}
+}
+
+func (p *Parser) Execute() (*Document, error) {
+	l := lexer.NewLexer(p.input, lexer.WithCommentTokens())
+	tokens, err := l.Execute()
+	if err != nil {
+		return nil, err
+	}
+	// コメントはトークンの間のテキストとして持つ
+	p.source = l.Input
+	for i, t := range *tokens {
+		if _, ok := t.(token.CommentToken); ok {
+			continue
+		}
+		p.tokens = append(p.tokens, t)
+		p.spans = append(p.spans, l.Spans()[i])
+	}
+
+	doc := &Document{bom: l.BOM()}
+	doc.leading = p.trivia()
+	if doc.root, err = p.parseValue(); err != nil {
+		return nil, err
+	}
+	doc.trailing = p.trivia()
+	// 値の後ろに余分なトークンがあってはいけない
+	if p.index < len(p.tokens) {
+		return nil, parser.ErrParse
+	}
+	return doc, nil
+}
+
+func (p *Parser) parseValue() (*Node, error) {
+	switch t := p.peek().(type) {
+	case token.LeftBraceToken:
+		return p.parseObject()
+	case token.LeftBracketToken:
+		return p.parseArray()
+	case token.StringToken, token.NumberToken, token.TrueToken, token.FalseToken, token.NullToken:
+		if _, err := parser.NewParser([]token.Token{t}).Execute(); err != nil {
+			return nil, err
+		}
+		span := p.spans[p.index]
+		p.next()
+		return &Node{kind: Scalar, text: string(p.source[span.Start:span.End]), token: t}, nil
+	}
+	return nil, parser.ErrParse
+}
+
+func (p *Parser) parseObject() (*Node, error) {
+	// { を読み飛ばす
+	p.next()
+	n := &Node{kind: Object}
+	leading := p.trivia()
+	if _, ok := p.peek().(token.RightBraceToken); ok {
+		p.next()
+		n.end = leading
+		return n, nil
+	}
+	for {
+		item := &Item{leading: leading}
+		key, ok := p.peek().(token.StringToken)
+		if !ok {
+			return nil, parser.ErrInvalidKeyValuePair
+		}
+		span := p.spans[p.index]
+		p.next()
+		item.key = key.Value()
+		item.rawKey = string(p.source[span.Start:span.End])
+		item.beforeColon = p.trivia()
+		if _, ok := p.next().(token.ColonToken); !ok {
+			return nil, parser.ErrInvalidKeyValuePair
+		}
+		item.afterColon = p.trivia()
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		item.value = v
+		item.trailing = p.trivia()
+		n.items = append(n.items, item)
+
+		switch p.next().(type) {
+		case token.CommaToken:
+			leading = p.trivia()
+			continue
+		case token.RightBraceToken:
+			item.trailing, n.end, n.trailingComma = splitTrailingComma(item.trailing)
+			return n, nil
+		}
+		return nil, parser.ErrParse
+	}
+}
+
+func (p *Parser) parseArray() (*Node, error) {
+	// [ を読み飛ばす
+	p.next()
+	n := &Node{kind: Array}
+	leading := p.trivia()
+	if _, ok := p.peek().(token.RightBracketToken); ok {
+		p.next()
+		n.end = leading
+		return n, nil
+	}
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, parser.ErrInvalidArrayValue
+		}
+		item := &Item{leading: leading, value: v, trailing: p.trivia()}
+		n.items = append(n.items, item)
+
+		switch p.next().(type) {
+		case token.CommaToken:
+			leading = p.trivia()
+			continue
+		case token.RightBracketToken:
+			item.trailing, n.end, n.trailingComma = splitTrailingComma(item.trailing)
+			return n, nil
+		}
+		return nil, parser.ErrParse
+	}
+}
+
+// trivia は次のトークンの前の空白とコメントを返す
+func (p *Parser) trivia() string {
+	end := len(p.source)
+	if p.index < len(p.spans) {
+		end = p.spans[p.index].Start
+	}
+	return string(p.source[p.pos:end])
+}
+
+// peek はトークンを読み終えていたらnilを返す
+func (p *Parser) peek() token.Token {
+	if p.index >= len(p.tokens) {
+		return nil
+	}
+	return p.tokens[p.index]
+}
+
+func (p *Parser) next() token.Token {
+	t := p.peek()
+	if t != nil {
+		p.pos = p.spans[p.index].End
+	}
+	p.index += 1
+	return t
+}
+
+// splitTrailingComma は最後の要素の後ろのテキストを末尾のカンマの前と後ろに分ける
+// lexerは末尾のカンマをトークンにしないので、コメントの外にあるカンマを探す
+func splitTrailingComma(s string) (before, after string, ok bool) {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == ',':
+			return s[:i], s[i+1:], true
+		case strings.HasPrefix(s[i:], "//"):
+			if j := strings.IndexByte(s[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(s)
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(s)
+			}
+		}
+	}
+	return s, "", false
+}
@@ -0,0 +1,273 @@
+package cst
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+const input = `// 設定
+{
+  "name":"app" ,  // 名前
+  "version": 1.50,
+  "tags": [ "a",
+    "b", /* 末尾 */ ],
+  "escaped": "あ",
+  "empty": { }
+}
+`
+
+func TestSuccessRoundTrip(t *testing.T) {
+	inputs := []string{
+		input,
+		`  [1, 2.0e1, true,null ]  `,
+		`"only"`,
+		"{\r\n\t\"a\" : [ ] , \"b\":{\"c\":-0}\r\n}",
+		"\uFEFF{\"bom\": true}",
+	}
+	for _, in := range inputs {
+		doc, err := NewParser(in).Execute()
+		if err != nil {
+			t.Fatalf("failed to parse %q %#v", in, err)
+		}
+		if got := doc.String(); got != in {
+			t.Errorf("want %q, but got %q", in, got)
+		}
+	}
+}
+
+func TestSuccessValue(t *testing.T) {
+	doc, err := NewParser(input).Execute()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	want := value.Object{
+		"name":    value.String("app"),
+		"version": value.NumberFloat(1.5),
+		"tags":    value.Array{value.String("a"), value.String("b")},
+		"escaped": value.String("あ"),
+		"empty":   value.Object{},
+	}
+	if diff := cmp.Diff(doc.Value(), want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(d *Document) error
+		want string
+	}{
+		{
+			name: "値を置き換える",
+			edit: func(d *Document) error {
+				return d.Set(pointer.Pointer{"tags", "1"}, value.Object{"x": value.Bool(true)})
+			},
+			want: `// 設定
+{
+  "name":"app" ,  // 名前
+  "version": 1.50,
+  "tags": [ "a",
+    {"x":true}, /* 末尾 */ ],
+  "escaped": "あ",
+  "empty": { }
+}
+`,
+		},
+		{
+			name: "キーを追加する",
+			edit: func(d *Document) error {
+				if err := d.InsertKey(pointer.Pointer{}, "new", value.NumberInt(2)); err != nil {
+					return err
+				}
+				return d.InsertKey(pointer.Pointer{"empty"}, "k", value.String("v"))
+			},
+			want: `// 設定
+{
+  "name":"app" ,  // 名前
+  "version": 1.50,
+  "tags": [ "a",
+    "b", /* 末尾 */ ],
+  "escaped": "あ",
+  "empty": {"k": "v" },
+  "new": 2
+}
+`,
+		},
+		{
+			name: "最初のメンバーと最後の要素を削除する",
+			edit: func(d *Document) error {
+				if err := d.Delete(pointer.Pointer{"name"}); err != nil {
+					return err
+				}
+				return d.Delete(pointer.Pointer{"tags", "1"})
+			},
+			want: `// 設定
+{
+  "version": 1.50,
+  "tags": [ "a", /* 末尾 */ ],
+  "escaped": "あ",
+  "empty": { }
+}
+`,
+		},
+		{
+			name: "最後のメンバーを削除する",
+			edit: func(d *Document) error {
+				return d.Delete(pointer.Pointer{"empty"})
+			},
+			want: `// 設定
+{
+  "name":"app" ,  // 名前
+  "version": 1.50,
+  "tags": [ "a",
+    "b", /* 末尾 */ ],
+  "escaped": "あ"
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := NewParser(input).Execute()
+			if err != nil {
+				t.Fatalf("failed to parse %#v", err)
+			}
+			if err := tt.edit(doc); err != nil {
+				t.Fatalf("failed to edit %#v", err)
+			}
+			if diff := cmp.Diff(doc.String(), tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+			// 編集した結果もパースできる
+			if _, err := NewParser(doc.String()).Execute(); err != nil {
+				t.Fatalf("failed to parse the edited document %#v", err)
+			}
+		})
+	}
+}
+
+func TestSuccessInsertKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "最後のメンバーの前にコメントがある",
+			input: "{\n  // name of the app\n  \"a\": 1\n}",
+			want:  "{\n  // name of the app\n  \"a\": 1,\n  \"b\": 2\n}",
+		},
+		{
+			name:  "最後のメンバーの後ろに行コメントがある",
+			input: "{\n  \"a\": 1 // x\n}",
+			want:  "{\n  \"a\": 1, // x\n  \"b\": 2\n}",
+		},
+		{
+			name:  "CRLFの改行",
+			input: "{\r\n\t\"a\": 1 /* x */\r\n}",
+			want:  "{\r\n\t\"a\": 1, /* x */\r\n\t\"b\": 2\r\n}",
+		},
+		{
+			name:  "1行のobject",
+			input: `{"a": 1, /* x */ "c": 3}`,
+			want:  `{"a": 1, /* x */ "c": 3, "b": 2}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := NewParser(tt.input).Execute()
+			if err != nil {
+				t.Fatalf("failed to parse %#v", err)
+			}
+			if err := doc.InsertKey(pointer.Pointer{}, "b", value.NumberInt(2)); err != nil {
+				t.Fatalf("failed to insert %#v", err)
+			}
+			if diff := cmp.Diff(doc.String(), tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(d *Document) error
+		want error
+	}{
+		{
+			name: "存在しないキー",
+			edit: func(d *Document) error { return d.Set(pointer.Pointer{"missing"}, value.Null) },
+			want: value.ErrPathNotFound,
+		},
+		{
+			name: "範囲外の添字",
+			edit: func(d *Document) error { return d.Delete(pointer.Pointer{"tags", "2"}) },
+			want: value.ErrPathNotFound,
+		},
+		{
+			name: "添字でないトークン",
+			edit: func(d *Document) error { return d.Delete(pointer.Pointer{"tags", "x"}) },
+			want: value.ErrInvalidIndex,
+		},
+		{
+			name: "既にあるキー",
+			edit: func(d *Document) error { return d.InsertKey(pointer.Pointer{}, "name", value.Null) },
+			want: ErrKeyExists,
+		},
+		{
+			name: "objectでない値にキーを追加する",
+			edit: func(d *Document) error { return d.InsertKey(pointer.Pointer{"tags"}, "k", value.Null) },
+			want: ErrNotObject,
+		},
+		{
+			name: "ルートを削除する",
+			edit: func(d *Document) error { return d.Delete(pointer.Pointer{}) },
+			want: ErrDeleteRoot,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := NewParser(input).Execute()
+			if err != nil {
+				t.Fatalf("failed to parse %#v", err)
+			}
+			if err := tt.edit(doc); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFailedParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "キーが文字列でない", input: `{1: 2}`, want: parser.ErrInvalidKeyValuePair},
+		{name: "値の後ろに余分なトークン", input: `[1] 2`, want: parser.ErrParse},
+		{name: "閉じていない配列", input: `[1, 2`, want: parser.ErrParse},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewParser(tt.input).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package cst
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrKeyExists  = errors.New("key already exists")
+	ErrNotObject  = errors.New("value is not an object")
+	ErrDeleteRoot = errors.New("cannot delete the root")
+)
+
+// Set はpathの位置にある値をvに置き換える
+// 置き換えた値は空白を入れずに出力し、それ以外の部分は元のテキストのまま残す
+func (d *Document) Set(path pointer.Pointer, v interface{}) error {
+	n, err := newNode(v)
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		d.root = n
+		return nil
+	}
+	parent, i, err := d.find(path)
+	if err != nil {
+		return err
+	}
+	parent.items[i].value = n
+	return nil
+}
+
+// InsertKey はpathの位置にあるobjectの末尾にkeyのメンバーを追加する
+// 字下げとコロンの前後の空白は最後のメンバーに合わせる
+func (d *Document) InsertKey(path pointer.Pointer, key string, v interface{}) error {
+	obj, err := d.lookup(path)
+	if err != nil {
+		return err
+	}
+	if obj.kind != Object {
+		return ErrNotObject
+	}
+	for _, item := range obj.items {
+		if item.key == key {
+			return ErrKeyExists
+		}
+	}
+	n, err := newNode(v)
+	if err != nil {
+		return err
+	}
+	rawKey, err := format(value.String(key))
+	if err != nil {
+		return err
+	}
+	item := &Item{key: key, rawKey: rawKey, afterColon: " ", value: n}
+	if len(obj.items) > 0 {
+		last := obj.items[len(obj.items)-1]
+		item.leading, item.beforeColon, item.afterColon = indent(last.leading), last.beforeColon, last.afterColon
+		// 閉じ括弧の前の改行は新しい最後のメンバーの後ろに移す
+		// 値の後ろのコメントはカンマの後ろに置いて、その値と同じ行に残す
+		if i := lastNewline(last.trailing); i >= 0 {
+			head := last.trailing[:i]
+			item.trailing, last.trailing = last.trailing[i:], ""
+			if strings.TrimSpace(head) != "" {
+				item.leading = head + item.leading
+			}
+		} else if strings.TrimSpace(last.trailing) == "" {
+			item.trailing, last.trailing = last.trailing, ""
+		}
+	}
+	obj.items = append(obj.items, item)
+	return nil
+}
+
+// indent はメンバーの前の空白とコメントから、最後の行の字下げだけを残す
+func indent(leading string) string {
+	i, j := lastNewline(leading), strings.LastIndex(leading, "\n")+1
+	line := leading[j:]
+	line = line[len(strings.TrimRight(line, " \t")):]
+	if i < 0 {
+		return line
+	}
+	return leading[i:j] + line
+}
+
+// lastNewline はsの最後の改行 (\r\nなら\rの位置) を返す
+func lastNewline(s string) int {
+	i := strings.LastIndex(s, "\n")
+	if i > 0 && s[i-1] == '\r' {
+		return i - 1
+	}
+	return i
+}
+
+// Delete はpathの位置にあるobjectのメンバーか配列の要素を削除する
+func (d *Document) Delete(path pointer.Pointer) error {
+	if len(path) == 0 {
+		return ErrDeleteRoot
+	}
+	parent, i, err := d.find(path)
+	if err != nil {
+		return err
+	}
+	items := parent.items
+	deleted := items[i]
+	switch {
+	case len(items) == 1:
+		parent.end = ""
+		parent.trailingComma = false
+	case i == 0:
+		// 開き括弧の後ろの空白を次の要素に引き継ぐ
+		items[1].leading = deleted.leading
+	case i == len(items)-1:
+		// 閉じ括弧の前の空白を前の要素に引き継ぐ
+		items[i-1].trailing = deleted.trailing
+	}
+	parent.items = append(items[:i:i], items[i+1:]...)
+	return nil
+}
+
+// lookup はpathの位置の値を返す
+func (d *Document) lookup(path pointer.Pointer) (*Node, error) {
+	if len(path) == 0 {
+		return d.root, nil
+	}
+	parent, i, err := d.find(path)
+	if err != nil {
+		return nil, err
+	}
+	return parent.items[i].value, nil
+}
+
+// find はpathの位置の値を持つobjectか配列と、その中での位置を返す
+func (d *Document) find(path pointer.Pointer) (*Node, int, error) {
+	n := d.root
+	var parent *Node
+	index := 0
+	for _, key := range path {
+		parent = n
+		switch n.kind {
+		case Object:
+			index = -1
+			for i, item := range n.items {
+				if item.key == key {
+					index = i
+				}
+			}
+			if index < 0 {
+				return nil, 0, value.ErrPathNotFound
+			}
+		case Array:
+			i, ok := pointer.Index(key)
+			if !ok {
+				return nil, 0, value.ErrInvalidIndex
+			}
+			if i >= len(n.items) {
+				return nil, 0, value.ErrPathNotFound
+			}
+			index = i
+		default:
+			return nil, 0, value.ErrPathNotFound
+		}
+		n = n.items[index].value
+	}
+	return parent, index, nil
+}
+
+// newNode はvを空白を入れずに出力してNodeにする
+func newNode(v interface{}) (*Node, error) {
+	text, err := format(v)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := NewParser(text).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return doc.root, nil
+}
+
+func format(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
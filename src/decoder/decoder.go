@@ -0,0 +1,609 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sam8helloworld/json-go/internal/structfield"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrInvalidTarget = errors.New("decode target must be a non-nil pointer")
+)
+
+// TypeError はJSONの値をGoの型に変換できなかったことを表す
+type TypeError struct {
+	// Path はJSONの値の位置
+	Path pointer.Pointer
+	// Value はJSONの値の説明
+	Value string
+	Type  reflect.Type
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("cannot decode %s into Go value of type %s at %q", e.Value, e.Type, e.Path.String())
+}
+
+// UnmarshalError はUnmarshalerなどの変換処理が失敗したことを表す
+type UnmarshalError struct {
+	Path pointer.Pointer
+	Type reflect.Type
+	Err  error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("cannot decode into Go value of type %s at %q: %v", e.Type, e.Path.String(), e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldError は構造体に対応するフィールドがないキーを表す
+type UnknownFieldError struct {
+	// Path はキーを持つobjectの位置
+	Path pointer.Pointer
+	Name string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q at %q", e.Name, e.Path.String())
+}
+
+// MissingFieldError は`required`が指定されたフィールドのキーがないことを表す
+type MissingFieldError struct {
+	// Path はキーがなかったobjectの位置
+	Path pointer.Pointer
+	Name string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("missing required field %q at %q", e.Name, e.Path.String())
+}
+
+// Errors は文書全体で見つかった複数のエラー
+// errors.Isとerrors.Asはいずれかのエラーに一致すれば成功する
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueUnmarshaler はこのライブラリの値から自身を復元できる型が実装する
+type ValueUnmarshaler interface {
+	UnmarshalJSONValue(v interface{}) error
+}
+
+// Unmarshaler はJSONのテキストから自身を復元できる型が実装する
+// encoding/jsonのjson.Unmarshalerと同じシグネチャなので、それを実装した型もそのまま使える
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+type Option func(*Decoder)
+
+// WithUnmarshalFunc はT型の値への変換方法を登録する
+// メソッドを追加できない外部の型にも使え、ValueUnmarshalerなどの実装より優先される
+func WithUnmarshalFunc[T any](fn func(v interface{}, dst *T) error) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(d *Decoder) {
+		if d.funcs == nil {
+			d.funcs = map[reflect.Type]func(interface{}, reflect.Value) error{}
+		}
+		d.funcs[t] = func(v interface{}, rv reflect.Value) error {
+			return fn(v, rv.Addr().Interface().(*T))
+		}
+	}
+}
+
+// WithDisallowUnknownFields は構造体のどのフィールドにも対応しないキーをエラーにする
+func WithDisallowUnknownFields() Option {
+	return func(d *Decoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+// WithCaseSensitive はキーとフィールド名を大文字小文字を区別して照合する
+// 指定しない場合は完全に一致するフィールドがなければ大文字小文字を区別せずに照合する
+func WithCaseSensitive() Option {
+	return func(d *Decoder) {
+		d.caseSensitive = true
+	}
+}
+
+// Unmarshal はJSONのテキストをパースしてvが指す値に格納する
+// value.Raw型のフィールドなどに入る値はパースせず、入力のテキストのまま格納する
+func Unmarshal(data []byte, v interface{}, opts ...Option) error {
+	l := lexer.NewLexer(string(data))
+	tokens, err := l.Execute()
+	if err != nil {
+		return err
+	}
+	d := NewDecoder(nil, opts...)
+	parserOpts := []parser.Option{}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		parserOpts = append(parserOpts, parser.WithSource(l.Input, l.Spans()), parser.WithRawFunc(d.rawFunc(rv.Type().Elem())))
+	}
+	val, err := parser.NewParser(*tokens, parserOpts...).Execute()
+	if err != nil {
+		return err
+	}
+	d.value = val
+	return d.Execute(v)
+}
+
+// Decoder はパース済みの値をGoの値に変換する
+type Decoder struct {
+	value interface{}
+	funcs map[reflect.Type]func(interface{}, reflect.Value) error
+
+	disallowUnknownFields bool
+	caseSensitive         bool
+	errs                  Errors
+}
+
+func NewDecoder(value interface{}, opts ...Option) *Decoder {
+	d := &Decoder{
+		value: value,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Execute はtargetが指す値に格納する
+// targetはnilでないポインタでなければならない
+// 変換できない値があっても文書の最後まで続け、見つかったエラーをすべて返す
+func (d *Decoder) Execute(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidTarget
+	}
+	d.errs = nil
+	d.decode(pointer.Pointer{}, d.value, rv.Elem())
+	switch len(d.errs) {
+	case 0:
+		return nil
+	case 1:
+		return d.errs[0]
+	}
+	return d.errs
+}
+
+func (d *Decoder) fail(err error) {
+	d.errs = append(d.errs, err)
+}
+
+func (d *Decoder) decode(path pointer.Pointer, v interface{}, rv reflect.Value) {
+	if rv.Type() == rawType {
+		d.decodeRaw(v, rv)
+		return
+	}
+	if raw, ok := v.(value.Raw); ok {
+		// パースを後回しにしていた値はここでパースする
+		parsed, err := parse(string(raw))
+		if err != nil {
+			d.fail(&UnmarshalError{Path: path, Type: rv.Type(), Err: err})
+			return
+		}
+		v = parsed
+	}
+	if handled, err := d.decodeHook(path, v, rv); handled {
+		if err != nil {
+			d.fail(err)
+		}
+		return
+	}
+	if value.IsNull(v) {
+		// nullはポインタ、map、スライス、interfaceをnilにし、それ以外は変更しない
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return
+	}
+
+	// value.Objectとvalue.ArrayにはGoの型にせずにvalueの型のまま格納する
+	switch rv.Type() {
+	case objectType, arrayType:
+		if reflect.TypeOf(v) == rv.Type() {
+			rv.Set(reflect.ValueOf(value.DeepCopy(v)))
+			return
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		d.decode(path, v, rv.Elem())
+		return
+	case reflect.Interface:
+		if rv.NumMethod() == 0 {
+			rv.Set(reflect.ValueOf(value.ToGo(v)))
+			return
+		}
+		// メソッドを持つinterfaceは既に入っている具体的な値に格納する
+		if !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr {
+			d.decode(path, v, rv.Elem())
+			return
+		}
+		d.fail(typeError(path, v, rv.Type()))
+		return
+	}
+
+	if s, ok := v.(value.String); ok {
+		if handled, err := decodeText(path, s, rv); handled {
+			if err != nil {
+				d.fail(err)
+			}
+			return
+		}
+	}
+
+	switch val := v.(type) {
+	case value.Object:
+		d.decodeObject(path, val, rv)
+	case value.Array:
+		d.decodeArray(path, val, rv)
+	case value.String:
+		if rv.Kind() != reflect.String {
+			d.fail(typeError(path, v, rv.Type()))
+			return
+		}
+		rv.SetString(string(val))
+	case value.Bool:
+		if rv.Kind() != reflect.Bool {
+			d.fail(typeError(path, v, rv.Type()))
+			return
+		}
+		rv.SetBool(bool(val))
+	case value.NumberInt, value.NumberFloat:
+		if err := decodeNumber(path, val, rv); err != nil {
+			d.fail(err)
+		}
+	default:
+		d.fail(typeError(path, v, rv.Type()))
+	}
+}
+
+func (d *Decoder) decodeObject(path pointer.Pointer, object value.Object, rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(path, object, rv)
+	case reflect.Map:
+		t := rv.Type()
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(t, len(object)))
+		}
+		for _, k := range value.SortedKeys(object) {
+			kv, err := mapKey(path.Append(k), k, t.Key())
+			if err != nil {
+				d.fail(err)
+				continue
+			}
+			ev := reflect.New(t.Elem()).Elem()
+			d.decode(path.Append(k), object[k], ev)
+			rv.SetMapIndex(kv, ev)
+		}
+	default:
+		d.fail(typeError(path, object, rv.Type()))
+	}
+}
+
+func (d *Decoder) decodeStruct(path pointer.Pointer, object value.Object, rv reflect.Value) {
+	fields := structfield.Fields(rv.Type())
+	found := map[string]bool{}
+	for _, k := range value.SortedKeys(object) {
+		f, ok := d.lookup(fields, k)
+		if !ok {
+			if d.disallowUnknownFields {
+				d.fail(&UnknownFieldError{Path: path, Name: k})
+			}
+			continue
+		}
+		found[f.Name] = true
+		fv, ok := structfield.Value(rv, f, true)
+		if !ok {
+			continue
+		}
+		v := object[k]
+		if f.Quoted {
+			unquoted, err := unquote(path.Append(k), v, fv.Type())
+			if err != nil {
+				d.fail(err)
+				continue
+			}
+			v = unquoted
+		}
+		d.decode(path.Append(k), v, fv)
+	}
+	for _, f := range fields {
+		if f.Required && !found[f.Name] {
+			d.fail(&MissingFieldError{Path: path, Name: f.Name})
+		}
+	}
+}
+
+func (d *Decoder) lookup(fields []structfield.Field, name string) (structfield.Field, bool) {
+	if d.caseSensitive {
+		return structfield.LookupExact(fields, name)
+	}
+	return structfield.Lookup(fields, name)
+}
+
+// decodeRaw はvalue.Raw型の値にJSONのテキストを格納する
+// パース済みの値はprinterでテキストに戻す
+func (d *Decoder) decodeRaw(v interface{}, rv reflect.Value) {
+	if raw, ok := v.(value.Raw); ok {
+		rv.SetString(string(raw))
+		return
+	}
+	var buf bytes.Buffer
+	if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+		d.fail(err)
+		return
+	}
+	rv.SetString(buf.String())
+}
+
+// rawFunc はtの値に格納するとき、value.Raw型の値に入る位置ならtrueを返す関数を作る
+// 途中に変換処理を任せる型や`string`オプションのフィールドがある位置はパースする
+func (d *Decoder) rawFunc(t reflect.Type) func(pointer.Pointer) bool {
+	return func(path pointer.Pointer) bool {
+		cur := t
+		for _, key := range path {
+			for cur.Kind() == reflect.Ptr {
+				cur = cur.Elem()
+			}
+			if d.hasHook(cur) {
+				return false
+			}
+			switch cur.Kind() {
+			case reflect.Struct:
+				f, ok := d.lookup(structfield.Fields(cur), key)
+				if !ok || f.Quoted {
+					return false
+				}
+				cur = f.Type
+			case reflect.Map, reflect.Slice, reflect.Array:
+				cur = cur.Elem()
+			default:
+				return false
+			}
+		}
+		return cur == rawType
+	}
+}
+
+func (d *Decoder) hasHook(t reflect.Type) bool {
+	if _, ok := d.funcs[t]; ok {
+		return true
+	}
+	pt := reflect.PtrTo(t)
+	return pt.Implements(valueUnmarshalerType) || pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+func (d *Decoder) decodeArray(path pointer.Pointer, array value.Array, rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(rv.Type(), len(array), len(array))
+		for i, vi := range array {
+			d.decode(path.AppendIndex(i), vi, slice.Index(i))
+		}
+		rv.Set(slice)
+	case reflect.Array:
+		// 固定長配列に収まらない要素は捨て、足りない要素はゼロ値にする
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(array) {
+				rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+				continue
+			}
+			d.decode(path.AppendIndex(i), array[i], rv.Index(i))
+		}
+	default:
+		d.fail(typeError(path, array, rv.Type()))
+	}
+}
+
+func decodeNumber(path pointer.Pointer, v interface{}, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt(v)
+		if !ok || rv.OverflowInt(i) {
+			return typeError(path, v, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := toInt(v)
+		if !ok || i < 0 || rv.OverflowUint(uint64(i)) {
+			return typeError(path, v, rv.Type())
+		}
+		rv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch n := v.(type) {
+		case value.NumberInt:
+			f = float64(n)
+		case value.NumberFloat:
+			f = float64(n)
+		}
+		if rv.OverflowFloat(f) {
+			return typeError(path, v, rv.Type())
+		}
+		rv.SetFloat(f)
+	default:
+		return typeError(path, v, rv.Type())
+	}
+	return nil
+}
+
+var (
+	rawType              = reflect.TypeOf(value.Raw(""))
+	objectType           = reflect.TypeOf(value.Object{})
+	arrayType            = reflect.TypeOf(value.Array{})
+	valueUnmarshalerType = reflect.TypeOf((*ValueUnmarshaler)(nil)).Elem()
+	unmarshalerType      = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeHook は登録された関数と、ValueUnmarshaler、Unmarshalerを実装した値に変換を任せる
+// ポインタ型の値にnullを格納する場合はnilにするだけで呼び出さない
+func (d *Decoder) decodeHook(path pointer.Pointer, v interface{}, rv reflect.Value) (bool, error) {
+	if !rv.CanAddr() || !rv.CanInterface() || rv.Kind() == reflect.Ptr && value.IsNull(v) {
+		return false, nil
+	}
+	var err error
+	if fn, ok := d.funcs[rv.Type()]; ok {
+		err = fn(v, rv)
+	} else {
+		switch m := rv.Addr().Interface().(type) {
+		case ValueUnmarshaler:
+			err = m.UnmarshalJSONValue(v)
+		case Unmarshaler:
+			var buf bytes.Buffer
+			if err = printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err == nil {
+				err = m.UnmarshalJSON(buf.Bytes())
+			}
+		default:
+			return false, nil
+		}
+	}
+	if err != nil {
+		return true, &UnmarshalError{Path: path, Type: rv.Type(), Err: err}
+	}
+	return true, nil
+}
+
+// decodeText は文字列をencoding.TextUnmarshalerを実装している値と[]byteに格納する
+// time.TimeはこれによってRFC 3339形式の文字列から変換される
+func decodeText(path pointer.Pointer, s value.String, rv reflect.Value) (bool, error) {
+	if rv.CanAddr() && reflect.PtrTo(rv.Type()).Implements(textUnmarshalerType) {
+		if err := rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return true, &TypeError{Path: path, Value: "string " + strconv.Quote(string(s)), Type: rv.Type()}
+		}
+		return true, nil
+	}
+	// []byteはBase64でエンコードされた文字列から変換する
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b, err := base64.StdEncoding.DecodeString(string(s))
+		if err != nil {
+			return true, &TypeError{Path: path, Value: "string", Type: rv.Type()}
+		}
+		rv.SetBytes(b)
+		return true, nil
+	}
+	return false, nil
+}
+
+// toInt は小数部のない数値を整数にする
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case value.NumberInt:
+		return int64(n), true
+	case value.NumberFloat:
+		f := float64(n)
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+func mapKey(path pointer.Pointer, k string, t reflect.Type) (reflect.Value, error) {
+	kv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		kv.SetString(k)
+		return kv, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(k, 10, 64)
+		if err == nil && !kv.OverflowInt(i) {
+			kv.SetInt(i)
+			return kv, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(k, 10, 64)
+		if err == nil && !kv.OverflowUint(u) {
+			kv.SetUint(u)
+			return kv, nil
+		}
+	}
+	return reflect.Value{}, &TypeError{Path: path, Value: "object key " + strconv.Quote(k), Type: t}
+}
+
+// unquote は`string`オプションが指定されたフィールドのために文字列の中身をJSONとしてパースする
+func unquote(path pointer.Pointer, v interface{}, t reflect.Type) (interface{}, error) {
+	s, ok := v.(value.String)
+	if !ok {
+		if value.IsNull(v) {
+			return v, nil
+		}
+		return nil, typeError(path, v, t)
+	}
+	inner, err := parse(string(s))
+	if err != nil {
+		return nil, typeError(path, v, t)
+	}
+	switch value.KindOf(inner) {
+	case value.KindObject, value.KindArray:
+		return nil, typeError(path, v, t)
+	}
+	return inner, nil
+}
+
+func parse(s string) (interface{}, error) {
+	tokens, err := lexer.NewLexer(s).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewParser(*tokens).Execute()
+}
+
+func typeError(path pointer.Pointer, v interface{}, t reflect.Type) error {
+	desc := value.KindOf(v).String()
+	switch n := v.(type) {
+	case value.NumberInt:
+		desc += " " + strconv.FormatInt(int64(n), 10)
+	case value.NumberFloat:
+		desc += " " + strconv.FormatFloat(float64(n), 'g', -1, 64)
+	}
+	return &TypeError{Path: path, Value: desc, Type: t}
+}
@@ -0,0 +1,493 @@
+package decoder
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/encoder"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+type Address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type Meta struct {
+	Version int `json:"version"`
+}
+
+type User struct {
+	Meta
+	Name     string            `json:"name"`
+	Age      uint8             `json:"age"`
+	Score    float32           `json:"score"`
+	Active   bool              `json:"active"`
+	Tags     []string          `json:"tags"`
+	Address  *Address          `json:"address"`
+	Attrs    map[string]int    `json:"attrs"`
+	Extra    interface{}       `json:"extra"`
+	Count    int64             `json:"count,string"`
+	Ignored  string            `json:"-"`
+	Nickname string            // タグなしはフィールド名と大文字小文字を区別せずに照合する
+	Codes    [2]int            `json:"codes"`
+	Lookup   map[int]string    `json:"lookup"`
+	Nullable *string           `json:"nullable"`
+	Nested   map[string][]bool `json:"nested"`
+}
+
+func TestSuccessUnmarshal(t *testing.T) {
+	input := `{
+		"version": 2,
+		"name": "taro",
+		"age": 20,
+		"score": 1.5,
+		"active": true,
+		"tags": ["a", "b"],
+		"address": {"city": "tokyo"},
+		"attrs": {"x": 1},
+		"extra": {"list": [1, 2.5, null]},
+		"count": "42",
+		"Ignored": "x",
+		"nickname": "taro-chan",
+		"codes": [1, 2, 3],
+		"lookup": {"1": "one"},
+		"nullable": null,
+		"nested": {"k": [true, false]},
+		"unknown": 1
+	}`
+	nullable := "keep"
+	got := User{Nullable: &nullable}
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	want := User{
+		Meta:     Meta{Version: 2},
+		Name:     "taro",
+		Age:      20,
+		Score:    1.5,
+		Active:   true,
+		Tags:     []string{"a", "b"},
+		Address:  &Address{City: "tokyo"},
+		Attrs:    map[string]int{"x": 1},
+		Extra:    map[string]interface{}{"list": []interface{}{int64(1), 2.5, nil}},
+		Count:    42,
+		Nickname: "taro-chan",
+		Codes:    [2]int{1, 2},
+		Lookup:   map[int]string{1: "one"},
+		Nested:   map[string][]bool{"k": {true, false}},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessUnmarshalScalars(t *testing.T) {
+	var i int
+	if err := Unmarshal([]byte(`1e3`), &i); err != nil || i != 1000 {
+		t.Errorf("want 1000, but got %d (%v)", i, err)
+	}
+	var p *string
+	if err := Unmarshal([]byte(`"x"`), &p); err != nil || p == nil || *p != "x" {
+		t.Errorf("want pointer to x, but got %v (%v)", p, err)
+	}
+	var v interface{}
+	if err := Unmarshal([]byte(`[]`), &v); err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	if diff := cmp.Diff(v, []interface{}{}); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessUnmarshalValueTypes(t *testing.T) {
+	var got struct {
+		Object value.Object `json:"object"`
+		Array  value.Array  `json:"array"`
+		Ptr    *value.Array `json:"ptr"`
+	}
+	input := `{"object": {"a": [1, 2.5, null], "b": {"c": "x"}}, "array": [true, {"d": 1}], "ptr": []}`
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	wantObject := value.Object{
+		"a": value.Array{value.NumberInt(1), value.NumberFloat(2.5), value.Null},
+		"b": value.Object{"c": value.String("x")},
+	}
+	if diff := cmp.Diff(got.Object, wantObject); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	wantArray := value.Array{value.Bool(true), value.Object{"d": value.NumberInt(1)}}
+	if diff := cmp.Diff(got.Array, wantArray); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if got.Ptr == nil || len(*got.Ptr) != 0 {
+		t.Fatalf("want pointer to empty array, but got %v", got.Ptr)
+	}
+}
+
+func TestSuccessUnmarshalEscape(t *testing.T) {
+	type S struct {
+		A string
+	}
+	var s S
+	if err := Unmarshal([]byte(`{"A":"x\ny\t\/\\\"\u0000\u3042\uD83D\uDE04"}`), &s); err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	if want := "x\ny\t/\\\"\x00あ😄"; s.A != want {
+		t.Fatalf("want %q, but got %q", want, s.A)
+	}
+
+	// Marshalした結果をUnmarshalすると元に戻る
+	for _, in := range []string{"x\ny", `a\b`, `\n`, "\"\b\f\r\t\x00\x1f", "/あ😄\u2028"} {
+		b, err := encoder.Marshal(S{A: in})
+		if err != nil {
+			t.Fatalf("failed to marshal %#v", err)
+		}
+		var got S
+		if err := Unmarshal(b, &got); err != nil {
+			t.Fatalf("failed to unmarshal %s %#v", b, err)
+		}
+		if got.A != in {
+			t.Errorf("want %q, but got %q from %s", in, got.A, b)
+		}
+	}
+}
+
+func TestFailedUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *TypeError
+	}{
+		{
+			name:  "文字列を数値に",
+			input: `{"name": 1}`,
+			want:  &TypeError{Path: pointer.Pointer{"name"}, Value: "number 1", Type: reflect.TypeOf("")},
+		},
+		{
+			name:  "範囲外の数値",
+			input: `{"age": 300}`,
+			want:  &TypeError{Path: pointer.Pointer{"age"}, Value: "number 300", Type: reflect.TypeOf(uint8(0))},
+		},
+		{
+			name:  "小数を整数に",
+			input: `{"version": 1.5}`,
+			want:  &TypeError{Path: pointer.Pointer{"version"}, Value: "number 1.5", Type: reflect.TypeOf(0)},
+		},
+		{
+			name:  "ネストした配列の要素",
+			input: `{"nested": {"k": [true, "x"]}}`,
+			want:  &TypeError{Path: pointer.Pointer{"nested", "k", "1"}, Value: "string", Type: reflect.TypeOf(true)},
+		},
+		{
+			name:  "objectを配列に",
+			input: `{"tags": {}}`,
+			want:  &TypeError{Path: pointer.Pointer{"tags"}, Value: "object", Type: reflect.TypeOf([]string{})},
+		},
+		{
+			name:  "数値にならないmapのキー",
+			input: `{"lookup": {"a": "x"}}`,
+			want:  &TypeError{Path: pointer.Pointer{"lookup", "a"}, Value: `object key "a"`, Type: reflect.TypeOf(0)},
+		},
+		{
+			name:  "stringオプションの中身が数値ではない",
+			input: `{"count": "abc"}`,
+			want:  &TypeError{Path: pointer.Pointer{"count"}, Value: "string", Type: reflect.TypeOf(int64(0))},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var u User
+			err := Unmarshal([]byte(tt.input), &u)
+			var got *TypeError
+			if !errors.As(err, &got) {
+				t.Fatalf("want TypeError, but got %v", err)
+			}
+			if diff := cmp.Diff(got, tt.want, cmp.Comparer(func(a, b reflect.Type) bool { return a == b })); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedUnmarshalInvalidInput(t *testing.T) {
+	var u User
+	if err := Unmarshal([]byte(`{"name": tru}`), &u); !errors.Is(err, lexer.ErrBoolTokenize) {
+		t.Errorf("want ErrBoolTokenize, but got %v", err)
+	}
+	if err := Unmarshal([]byte(`{}`), u); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("want ErrInvalidTarget, but got %v", err)
+	}
+}
+
+func TestTypeErrorMessage(t *testing.T) {
+	err := &TypeError{Path: pointer.Pointer{"a", "0"}, Value: "number 1", Type: reflect.TypeOf("")}
+	want := `cannot decode number 1 into Go value of type string at "/a/0"`
+	if got := err.Error(); got != want {
+		t.Errorf("want %s, but got %s", want, got)
+	}
+}
+
+// Money は"1200JPY"のような文字列で表す金額
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m *Money) UnmarshalJSONValue(v interface{}) error {
+	s, ok := v.(value.String)
+	if !ok || len(s) < 4 {
+		return errors.New("invalid money")
+	}
+	amount, err := strconv.ParseInt(string(s[:len(s)-3]), 10, 64)
+	if err != nil {
+		return err
+	}
+	m.Amount, m.Currency = amount, string(s[len(s)-3:])
+	return nil
+}
+
+// Code はencoding/jsonのjson.Unmarshalerを実装した型
+type Code string
+
+func (c *Code) UnmarshalJSON(b []byte) error {
+	*c = Code("code:" + string(b))
+	return nil
+}
+
+type Order struct {
+	Price    Money         `json:"price"`
+	Discount *Money        `json:"discount"`
+	Code     Code          `json:"code"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+func TestSuccessUnmarshalHooks(t *testing.T) {
+	input := `{"price": "1200JPY", "discount": null, "code": [1, "a"], "timeout": "1m30s"}`
+	got := Order{Discount: &Money{}}
+	err := Unmarshal([]byte(input), &got, WithUnmarshalFunc(func(v interface{}, dst *time.Duration) error {
+		s, ok := v.(value.String)
+		if !ok {
+			return errors.New("duration must be a string")
+		}
+		d, err := time.ParseDuration(string(s))
+		*dst = d
+		return err
+	}))
+	if err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	want := Order{
+		Price:   Money{Amount: 1200, Currency: "JPY"},
+		Code:    Code(`code:[1,"a"]`),
+		Timeout: 90 * time.Second,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedUnmarshalHooks(t *testing.T) {
+	var got Order
+	err := Unmarshal([]byte(`{"price": 1}`), &got)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) {
+		t.Fatalf("want UnmarshalError, but got %v", err)
+	}
+	if ue.Path.String() != "/price" || ue.Type != reflect.TypeOf(Money{}) {
+		t.Errorf("unexpected error %v", ue)
+	}
+}
+
+type Account struct {
+	ID      int      `json:"id,required"`
+	Email   string   `json:"email,required"`
+	Profile *Profile `json:"profile"`
+}
+
+type Profile struct {
+	DisplayName string `json:"displayName,required"`
+}
+
+func TestSuccessUnmarshalStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  Account
+	}{
+		{
+			name:  "必須フィールドがすべてある",
+			input: `{"id": 1, "email": "a@example.com", "profile": {"displayName": "a"}}`,
+			opts:  []Option{WithDisallowUnknownFields(), WithCaseSensitive()},
+			want:  Account{ID: 1, Email: "a@example.com", Profile: &Profile{DisplayName: "a"}},
+		},
+		{
+			name:  "大文字小文字を区別しない照合でも必須フィールドを満たす",
+			input: `{"ID": 1, "Email": "a@example.com"}`,
+			want:  Account{ID: 1, Email: "a@example.com"},
+		},
+		{
+			name:  "nullでもキーがあれば必須フィールドを満たす",
+			input: `{"id": 1, "email": null}`,
+			opts:  []Option{WithDisallowUnknownFields()},
+			want:  Account{ID: 1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got Account
+			if err := Unmarshal([]byte(tt.input), &got, tt.opts...); err != nil {
+				t.Fatalf("failed to unmarshal %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedUnmarshalStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  []string
+	}{
+		{
+			name:  "未知のキー",
+			input: `{"id": 1, "email": "a", "extra": true, "profile": {"displayName": "a", "age": 3}}`,
+			opts:  []Option{WithDisallowUnknownFields()},
+			want: []string{
+				`unknown field "extra" at ""`,
+				`unknown field "age" at "/profile"`,
+			},
+		},
+		{
+			name:  "必須フィールドがない",
+			input: `{"profile": {}}`,
+			want: []string{
+				`missing required field "displayName" at "/profile"`,
+				`missing required field "id" at ""`,
+				`missing required field "email" at ""`,
+			},
+		},
+		{
+			name:  "大文字小文字を区別する照合",
+			input: `{"ID": 1, "email": "a"}`,
+			opts:  []Option{WithCaseSensitive(), WithDisallowUnknownFields()},
+			want: []string{
+				`unknown field "ID" at ""`,
+				`missing required field "id" at ""`,
+			},
+		},
+		{
+			name:  "型の誤りもまとめて報告する",
+			input: `{"id": "1", "email": 2, "profile": {"displayName": "a"}}`,
+			want: []string{
+				`cannot decode number 2 into Go value of type string at "/email"`,
+				`cannot decode string into Go value of type int at "/id"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got Account
+			err := Unmarshal([]byte(tt.input), &got, tt.opts...)
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("want Errors, but got %v", err)
+			}
+			msgs := make([]string, len(errs))
+			for i, e := range errs {
+				msgs[i] = e.Error()
+			}
+			if diff := cmp.Diff(msgs, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	err := Unmarshal([]byte(`{"id": "x", "profile": 1}`), &Account{}, WithDisallowUnknownFields())
+	var te *TypeError
+	if !errors.As(err, &te) || te.Path.String() != "/id" {
+		t.Errorf("want TypeError at /id, but got %v", err)
+	}
+	var me *MissingFieldError
+	if !errors.As(err, &me) || me.Name != "email" {
+		t.Errorf("want MissingFieldError for email, but got %v", err)
+	}
+	want := `cannot decode string into Go value of type int at "/id"; ` +
+		`cannot decode number 1 into Go value of type decoder.Profile at "/profile"; ` +
+		`missing required field "email" at ""`
+	if got := err.Error(); got != want {
+		t.Errorf("want %s, but got %s", want, got)
+	}
+
+	err = Unmarshal([]byte(`{"id": 1}`), &Account{})
+	if _, ok := err.(*MissingFieldError); !ok {
+		t.Errorf("want a single MissingFieldError, but got %#v", err)
+	}
+}
+
+type Envelope struct {
+	Type    string               `json:"type"`
+	Payload value.Raw            `json:"payload"`
+	Extras  map[string]value.Raw `json:"extras"`
+}
+
+func TestSuccessUnmarshalRaw(t *testing.T) {
+	input := `{"type": "user", "payload": {"name" : "taro",  "age": 20}, "extras": {"a": [1, 2 ], "b": null}}`
+	var got Envelope
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatalf("failed to unmarshal %#v", err)
+	}
+	want := Envelope{
+		Type:    "user",
+		Payload: value.Raw(`{"name" : "taro",  "age": 20}`),
+		Extras:  map[string]value.Raw{"a": "[1, 2 ]", "b": "null"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+
+	// 後からパースして格納する
+	var user User
+	if err := NewDecoder(got.Payload).Execute(&user); err != nil {
+		t.Fatalf("failed to decode payload %#v", err)
+	}
+	if user.Name != "taro" || user.Age != 20 {
+		t.Errorf("unexpected user %#v", user)
+	}
+
+	// パース済みの値はテキストに戻して格納する
+	var env Envelope
+	err := NewDecoder(value.Object{"payload": value.Array{value.NumberInt(1)}}).Execute(&env)
+	if err != nil || env.Payload != "[1]" {
+		t.Errorf("want [1], but got %s (%v)", env.Payload, err)
+	}
+}
+
+func TestFailedUnmarshalRaw(t *testing.T) {
+	var user User
+	err := NewDecoder(value.Raw(`{"name": tru}`)).Execute(&user)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || !errors.Is(err, lexer.ErrBoolTokenize) {
+		t.Fatalf("want UnmarshalError, but got %v", err)
+	}
+}
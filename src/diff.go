@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sam8helloworld/json-go/diff"
+)
+
+// runDiff は2つのJSONファイルを構造的に比較する
+// 差分がなければ0、あれば1、エラーの場合は2を返す
+//
+//	json-go diff [-format human|unified|json] [-array index|lcs|key=NAME] a.json b.json
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := fs.String("format", "human", "出力形式 (human, unified, json)")
+	array := fs.String("array", "index", "配列の比較方法 (index, lcs, key=NAME)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: json-go diff [flags] a.json b.json")
+		return 2
+	}
+
+	var opts []diff.Option
+	switch {
+	case *array == "index":
+		opts = append(opts, diff.WithArrayMode(diff.ArrayByIndex))
+	case *array == "lcs":
+		opts = append(opts, diff.WithArrayMode(diff.ArrayByLCS))
+	case strings.HasPrefix(*array, "key="):
+		opts = append(opts, diff.WithArrayKey(strings.TrimPrefix(*array, "key=")))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown array mode: %s\n", *array)
+		return 2
+	}
+
+	from, err := load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	to, err := load(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	changes := diff.NewDiffer(from, to, opts...).Execute()
+
+	switch *format {
+	case "human":
+		err = diff.WriteHuman(os.Stdout, changes)
+	case "unified":
+		err = diff.WriteUnified(os.Stdout, fs.Arg(0), fs.Arg(1), changes)
+	case "json":
+		err = diff.WriteJSON(os.Stdout, changes)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,251 @@
+package diff
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+type Op int
+
+const (
+	OpAdd Op = iota
+	OpRemove
+	OpReplace
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpAdd:
+		return "add"
+	case OpRemove:
+		return "remove"
+	case OpReplace:
+		return "replace"
+	}
+	return "unknown"
+}
+
+// Change は1箇所の差分を表す
+// Removeのパスは変更前、Addのパスは変更後の文書での位置を指す
+type Change struct {
+	Op   Op
+	Path pointer.Pointer
+	From interface{}
+	To   interface{}
+}
+
+// ArrayMode は配列の要素同士をどう対応付けるかを表す
+type ArrayMode int
+
+const (
+	// ArrayByIndex は同じ添字の要素同士を比較する
+	ArrayByIndex ArrayMode = iota
+	// ArrayByLCS は最長共通部分列で一致する要素を対応付ける
+	ArrayByLCS
+	// ArrayByKey はobjectの要素を指定したキーの値で対応付ける
+	ArrayByKey
+)
+
+type Option func(*Differ)
+
+func WithArrayMode(mode ArrayMode) Option {
+	return func(d *Differ) {
+		d.arrayMode = mode
+	}
+}
+
+// WithArrayKey は配列の要素をkeyの値で対応付ける
+func WithArrayKey(key string) Option {
+	return func(d *Differ) {
+		d.arrayMode = ArrayByKey
+		d.arrayKey = key
+	}
+}
+
+type Differ struct {
+	from      interface{}
+	to        interface{}
+	arrayMode ArrayMode
+	arrayKey  string
+	changes   []Change
+}
+
+func NewDiffer(from, to interface{}, opts ...Option) *Differ {
+	d := &Differ{
+		from: from,
+		to:   to,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *Differ) Execute() []Change {
+	d.changes = []Change{}
+	d.compare(pointer.Pointer{}, d.from, d.to)
+	return d.changes
+}
+
+func (d *Differ) compare(path pointer.Pointer, a, b interface{}) {
+	switch av := a.(type) {
+	case value.Object:
+		if bv, ok := b.(value.Object); ok {
+			d.compareObject(path, av, bv)
+			return
+		}
+	case value.Array:
+		if bv, ok := b.(value.Array); ok {
+			switch d.arrayMode {
+			case ArrayByLCS:
+				d.compareArrayLCS(path, av, bv)
+			case ArrayByKey:
+				d.compareArrayKey(path, av, bv)
+			default:
+				d.compareArrayIndex(path, av, bv)
+			}
+			return
+		}
+	}
+	if !value.Equal(a, b) {
+		d.changes = append(d.changes, Change{Op: OpReplace, Path: path, From: a, To: b})
+	}
+}
+
+func (d *Differ) compareObject(path pointer.Pointer, a, b value.Object) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		av, aok := a[k]
+		bv, bok := b[k]
+		switch {
+		case aok && bok:
+			d.compare(path.Append(k), av, bv)
+		case aok:
+			d.changes = append(d.changes, Change{Op: OpRemove, Path: path.Append(k), From: av})
+		default:
+			d.changes = append(d.changes, Change{Op: OpAdd, Path: path.Append(k), To: bv})
+		}
+	}
+}
+
+func (d *Differ) compareArrayIndex(path pointer.Pointer, a, b value.Array) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i < len(a) && i < len(b):
+			d.compare(path.AppendIndex(i), a[i], b[i])
+		case i < len(a):
+			d.changes = append(d.changes, Change{Op: OpRemove, Path: path.AppendIndex(i), From: a[i]})
+		default:
+			d.changes = append(d.changes, Change{Op: OpAdd, Path: path.AppendIndex(i), To: b[i]})
+		}
+	}
+}
+
+func (d *Differ) compareArrayLCS(path pointer.Pointer, a, b value.Array) {
+	// lengths[i][j]はa[i:]とb[j:]の最長共通部分列の長さ
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case value.Equal(a[i], b[j]):
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	// 一致した要素の間に残った要素同士は先頭から順に対応付けて比較する
+	i, j := 0, 0
+	gapA, gapB := []int{}, []int{}
+	flush := func() {
+		for k := 0; k < len(gapA) || k < len(gapB); k++ {
+			switch {
+			case k < len(gapA) && k < len(gapB):
+				d.compare(path.AppendIndex(gapA[k]), a[gapA[k]], b[gapB[k]])
+			case k < len(gapA):
+				d.changes = append(d.changes, Change{Op: OpRemove, Path: path.AppendIndex(gapA[k]), From: a[gapA[k]]})
+			default:
+				d.changes = append(d.changes, Change{Op: OpAdd, Path: path.AppendIndex(gapB[k]), To: b[gapB[k]]})
+			}
+		}
+		gapA, gapB = gapA[:0], gapB[:0]
+	}
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && value.Equal(a[i], b[j]):
+			flush()
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lengths[i+1][j] >= lengths[i][j+1]):
+			gapA = append(gapA, i)
+			i++
+		default:
+			gapB = append(gapB, j)
+			j++
+		}
+	}
+	flush()
+}
+
+func (d *Differ) compareArrayKey(path pointer.Pointer, a, b value.Array) {
+	// 同じ識別子を持つ要素が複数ある場合は出現順に対応付ける
+	indexes := map[string][]int{}
+	for j, e := range b {
+		id := d.identity(e)
+		indexes[id] = append(indexes[id], j)
+	}
+	matched := make([]bool, len(b))
+	for i, e := range a {
+		id := d.identity(e)
+		if js := indexes[id]; len(js) > 0 {
+			indexes[id] = js[1:]
+			matched[js[0]] = true
+			d.compare(path.AppendIndex(i), e, b[js[0]])
+			continue
+		}
+		d.changes = append(d.changes, Change{Op: OpRemove, Path: path.AppendIndex(i), From: e})
+	}
+	for j, e := range b {
+		if !matched[j] {
+			d.changes = append(d.changes, Change{Op: OpAdd, Path: path.AppendIndex(j), To: e})
+		}
+	}
+}
+
+// identity は要素の識別子を返す
+// キーを持たない要素は内容そのものを識別子にする
+func (d *Differ) identity(e interface{}) string {
+	if o, ok := e.(value.Object); ok {
+		if k, ok := o[d.arrayKey]; ok {
+			return "k" + format(k)
+		}
+	}
+	return "v" + format(e)
+}
+
+func format(v interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+		return "<invalid>"
+	}
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+func TestSuccess(t *testing.T) {
+	tests := []struct {
+		name string
+		from interface{}
+		to   interface{}
+		opts []Option
+		want []Change
+	}{
+		{
+			name: "差分なし",
+			from: value.Object{"a": value.NumberInt(1)},
+			to:   value.Object{"a": value.NumberInt(1)},
+			want: []Change{},
+		},
+		{
+			name: "objectのキーの追加・削除・変更",
+			from: value.Object{
+				"a": value.NumberInt(1),
+				"b": value.String("x"),
+				"c": value.Object{"d": value.Bool(true)},
+			},
+			to: value.Object{
+				"a": value.NumberInt(2),
+				"c": value.Object{"d": value.Bool(true), "e": value.Null},
+			},
+			want: []Change{
+				{Op: OpReplace, Path: pointer.Pointer{"a"}, From: value.NumberInt(1), To: value.NumberInt(2)},
+				{Op: OpRemove, Path: pointer.Pointer{"b"}, From: value.String("x")},
+				{Op: OpAdd, Path: pointer.Pointer{"c", "e"}, To: value.Null},
+			},
+		},
+		{
+			name: "型が変わったら置き換え",
+			from: value.Object{"a": value.Array{}},
+			to:   value.Object{"a": value.Object{}},
+			want: []Change{
+				{Op: OpReplace, Path: pointer.Pointer{"a"}, From: value.Array{}, To: value.Object{}},
+			},
+		},
+		{
+			name: "配列を添字で比較",
+			from: value.Array{value.NumberInt(1), value.NumberInt(2), value.NumberInt(3)},
+			to:   value.Array{value.NumberInt(2), value.NumberInt(3)},
+			want: []Change{
+				{Op: OpReplace, Path: pointer.Pointer{"0"}, From: value.NumberInt(1), To: value.NumberInt(2)},
+				{Op: OpReplace, Path: pointer.Pointer{"1"}, From: value.NumberInt(2), To: value.NumberInt(3)},
+				{Op: OpRemove, Path: pointer.Pointer{"2"}, From: value.NumberInt(3)},
+			},
+		},
+		{
+			name: "配列をLCSで比較",
+			from: value.Array{value.NumberInt(1), value.NumberInt(2), value.NumberInt(3)},
+			to:   value.Array{value.NumberInt(2), value.NumberInt(3), value.NumberInt(4)},
+			opts: []Option{WithArrayMode(ArrayByLCS)},
+			want: []Change{
+				{Op: OpRemove, Path: pointer.Pointer{"0"}, From: value.NumberInt(1)},
+				{Op: OpAdd, Path: pointer.Pointer{"2"}, To: value.NumberInt(4)},
+			},
+		},
+		{
+			name: "LCSで一致しなかった要素同士は中身を比較",
+			from: value.Array{value.String("a"), value.Object{"x": value.NumberInt(1)}, value.String("b")},
+			to:   value.Array{value.String("a"), value.Object{"x": value.NumberInt(2)}, value.String("b")},
+			opts: []Option{WithArrayMode(ArrayByLCS)},
+			want: []Change{
+				{Op: OpReplace, Path: pointer.Pointer{"1", "x"}, From: value.NumberInt(1), To: value.NumberInt(2)},
+			},
+		},
+		{
+			name: "配列をキーで比較",
+			from: value.Array{
+				value.Object{"id": value.NumberInt(1), "v": value.String("a")},
+				value.Object{"id": value.NumberInt(2), "v": value.String("b")},
+			},
+			to: value.Array{
+				value.Object{"id": value.NumberInt(3), "v": value.String("c")},
+				value.Object{"id": value.NumberInt(1), "v": value.String("z")},
+			},
+			opts: []Option{WithArrayKey("id")},
+			want: []Change{
+				{Op: OpReplace, Path: pointer.Pointer{"0", "v"}, From: value.String("a"), To: value.String("z")},
+				{Op: OpRemove, Path: pointer.Pointer{"1"}, From: value.Object{"id": value.NumberInt(2), "v": value.String("b")}},
+				{Op: OpAdd, Path: pointer.Pointer{"0"}, To: value.Object{"id": value.NumberInt(3), "v": value.String("c")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sut := NewDiffer(tt.from, tt.to, tt.opts...)
+			got := sut.Execute()
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	changes := []Change{
+		{Op: OpAdd, Path: pointer.Pointer{"a"}, To: value.NumberInt(1)},
+		{Op: OpRemove, Path: pointer.Pointer{"b/c"}, From: value.String("x")},
+		{Op: OpReplace, Path: pointer.Pointer{"d", "0"}, From: value.Bool(true), To: value.Null},
+	}
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		want  string
+	}{
+		{
+			name: "human",
+			write: func(buf *bytes.Buffer) error {
+				return WriteHuman(buf, changes)
+			},
+			want: "+ /a: 1\n- /b~1c: \"x\"\n~ /d/0: true -> null\n",
+		},
+		{
+			name: "unified",
+			write: func(buf *bytes.Buffer) error {
+				return WriteUnified(buf, "a.json", "b.json", changes)
+			},
+			want: "--- a.json\n+++ b.json\n@@ /a @@\n+1\n@@ /b~1c @@\n-\"x\"\n@@ /d/0 @@\n-true\n+null\n",
+		},
+		{
+			name: "json",
+			write: func(buf *bytes.Buffer) error {
+				return WriteJSON(buf, changes)
+			},
+			want: `[{"op":"add","path":"/a","to":1},{"from":"x","op":"remove","path":"/b~1c"},{"from":true,"op":"replace","path":"/d/0","to":null}]` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatalf("failed to write diff %#v", err)
+			}
+			if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
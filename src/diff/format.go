@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+// WriteHuman は差分を1行1件の読みやすい形式で書き出す
+// 行頭の記号は追加が+、削除が-、変更が~
+func WriteHuman(w io.Writer, changes []Change) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range changes {
+		switch c.Op {
+		case OpAdd:
+			bw.WriteString("+ " + c.Path.String() + ": " + format(c.To) + "\n")
+		case OpRemove:
+			bw.WriteString("- " + c.Path.String() + ": " + format(c.From) + "\n")
+		case OpReplace:
+			bw.WriteString("~ " + c.Path.String() + ": " + format(c.From) + " -> " + format(c.To) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteUnified はunified diffに似た形式で差分を書き出す
+// 差分ごとにパスを見出しにして変更前後の値を-と+の行で示す
+func WriteUnified(w io.Writer, fromName, toName string, changes []Change) error {
+	bw := bufio.NewWriter(w)
+	if len(changes) == 0 {
+		return bw.Flush()
+	}
+	bw.WriteString("--- " + fromName + "\n")
+	bw.WriteString("+++ " + toName + "\n")
+	for _, c := range changes {
+		bw.WriteString("@@ " + c.Path.String() + " @@\n")
+		if c.Op != OpAdd {
+			bw.WriteString("-" + format(c.From) + "\n")
+		}
+		if c.Op != OpRemove {
+			bw.WriteString("+" + format(c.To) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteJSON は差分をobjectの配列として書き出す
+//
+//	[{"op":"replace","path":"/c","from":1,"to":2}]
+func WriteJSON(w io.Writer, changes []Change) error {
+	array := value.Array{}
+	for _, c := range changes {
+		object := value.Object{
+			"op":   value.String(c.Op.String()),
+			"path": value.String(c.Path.String()),
+		}
+		if c.Op != OpAdd {
+			object["from"] = c.From
+		}
+		if c.Op != OpRemove {
+			object["to"] = c.To
+		}
+		array = append(array, object)
+	}
+	if err := printer.NewPrinter(array, printer.WithWriter(w)).Execute(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,371 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/sam8helloworld/json-go/internal/structfield"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrUnsupportedType  = errors.New("unsupported type")
+	ErrUnsupportedValue = errors.New("unsupported value")
+)
+
+// MarshalError はGoの値をJSONの値に変換できなかったことを表す
+type MarshalError struct {
+	// Path は変換後のJSONでの位置
+	Path pointer.Pointer
+	Type reflect.Type
+	Err  error
+}
+
+func (e *MarshalError) Error() string {
+	return fmt.Sprintf("cannot encode Go value of type %s at %q: %v", e.Type, e.Path.String(), e.Err)
+}
+
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// ValueMarshaler は自身をこのライブラリの値に変換できる型が実装する
+type ValueMarshaler interface {
+	MarshalJSONValue() (interface{}, error)
+}
+
+// Marshaler は自身をJSONのテキストに変換できる型が実装する
+// encoding/jsonのjson.Marshalerと同じシグネチャなので、それを実装した型もそのまま使える
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+type Option func(*Encoder)
+
+// WithMarshalFunc はT型の値の変換方法を登録する
+// メソッドを追加できない外部の型にも使え、ValueMarshalerなどの実装より優先される
+// Tがinterfaceの場合はそれを実装した型の値に使う
+func WithMarshalFunc[T any](fn func(T) (interface{}, error)) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(e *Encoder) {
+		e.funcs = append(e.funcs, marshalFunc{
+			typ: t,
+			fn: func(rv reflect.Value) (interface{}, error) {
+				return fn(rv.Interface().(T))
+			},
+		})
+	}
+}
+
+type marshalFunc struct {
+	typ reflect.Type
+	fn  func(reflect.Value) (interface{}, error)
+}
+
+// Marshal はGoの値をJSONのテキストにする
+// 出力はprinter.Printerと同じ規則でエスケープされ、objectのキーはソートされる
+func Marshal(v interface{}, opts ...Option) ([]byte, error) {
+	val, err := NewEncoder(v, opts...).Execute()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := printer.NewPrinter(val, printer.WithWriter(&buf)).Execute(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encoder はGoの値をこのライブラリの値に変換する
+type Encoder struct {
+	value interface{}
+	funcs []marshalFunc
+	// seen は走査中のポインタ、スライス、mapで、循環参照を見つけるのに使う
+	seen map[visit]bool
+}
+
+// visit はポインタ、スライス、mapの参照先
+// 同じ配列の長さの違うスライスは別の値なので長さも含める
+type visit struct {
+	ptr uintptr
+	len int
+}
+
+func NewEncoder(v interface{}, opts ...Option) *Encoder {
+	e := &Encoder{
+		value: v,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+var (
+	rawType            = reflect.TypeOf(value.Raw(""))
+	valueMarshalerType = reflect.TypeOf((*ValueMarshaler)(nil)).Elem()
+	marshalerType      = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (e *Encoder) Execute() (interface{}, error) {
+	e.seen = map[visit]bool{}
+	return e.encode(pointer.Pointer{}, reflect.ValueOf(e.value))
+}
+
+func (e *Encoder) encode(path pointer.Pointer, rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return value.Null, nil
+	}
+	if rv.Type() == rawType {
+		// 不正なテキストをそのまま出力しないよう検査だけする
+		if _, err := parse([]byte(rv.String())); err != nil {
+			return nil, &MarshalError{Path: path, Type: rv.Type(), Err: err}
+		}
+		return value.Raw(rv.String()), nil
+	}
+	if v, ok, err := e.encodeHook(path, rv); ok {
+		return v, err
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return value.Bool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.NumberInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return nil, &MarshalError{Path: path, Type: rv.Type(), Err: ErrUnsupportedValue}
+		}
+		return value.NumberInt(u), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, &MarshalError{Path: path, Type: rv.Type(), Err: ErrUnsupportedValue}
+		}
+		return value.NumberFloat(f), nil
+	case reflect.String:
+		return value.String(rv.String()), nil
+	case reflect.Interface:
+		if rv.IsNil() {
+			return value.Null, nil
+		}
+		return e.encode(path, rv.Elem())
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return value.Null, nil
+		}
+		if err := e.enter(path, rv); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		return e.encode(path, rv.Elem())
+	case reflect.Slice:
+		if rv.IsNil() {
+			return value.Null, nil
+		}
+		// []byteはBase64でエンコードした文字列にする
+		if rv.Type().Elem().Kind() == reflect.Uint8 && !rv.Type().Elem().Implements(textMarshalerType) {
+			return value.String(base64.StdEncoding.EncodeToString(rv.Bytes())), nil
+		}
+		if err := e.enter(path, rv); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		return e.encodeArray(path, rv)
+	case reflect.Array:
+		return e.encodeArray(path, rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return value.Null, nil
+		}
+		if err := e.enter(path, rv); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		return e.encodeMap(path, rv)
+	case reflect.Struct:
+		return e.encodeStruct(path, rv)
+	}
+	return nil, &MarshalError{Path: path, Type: rv.Type(), Err: ErrUnsupportedType}
+}
+
+// enter はrvを走査中として記録する
+// 既に走査中の場合は循環参照で、無限に再帰してしまうのでエラーにする
+func (e *Encoder) enter(path pointer.Pointer, rv reflect.Value) error {
+	k := visitOf(rv)
+	if e.seen[k] {
+		return &MarshalError{Path: path, Type: rv.Type(), Err: ErrUnsupportedValue}
+	}
+	e.seen[k] = true
+	return nil
+}
+
+func (e *Encoder) leave(rv reflect.Value) {
+	delete(e.seen, visitOf(rv))
+}
+
+func visitOf(rv reflect.Value) visit {
+	if rv.Kind() == reflect.Slice {
+		return visit{ptr: rv.Pointer(), len: rv.Len()}
+	}
+	return visit{ptr: rv.Pointer()}
+}
+
+// encodeHook は登録された関数と、ValueMarshaler、Marshaler、encoding.TextMarshalerを実装した値を変換する
+// time.Timeはencoding.TextMarshalerによってRFC 3339形式の文字列になる
+func (e *Encoder) encodeHook(path pointer.Pointer, rv reflect.Value) (interface{}, bool, error) {
+	if rv.Kind() == reflect.Interface || !rv.CanInterface() {
+		return nil, false, nil
+	}
+	for _, f := range e.funcs {
+		if rv.Type() == f.typ || (f.typ.Kind() == reflect.Interface && rv.Type().Implements(f.typ)) {
+			v, err := f.fn(rv)
+			if err != nil {
+				return nil, true, &MarshalError{Path: path, Type: rv.Type(), Err: err}
+			}
+			return v, true, nil
+		}
+	}
+
+	// ポインタのレシーバで実装している場合も使えるようにする
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() {
+		pt := reflect.PtrTo(rv.Type())
+		if pt.Implements(valueMarshalerType) || pt.Implements(marshalerType) || pt.Implements(textMarshalerType) {
+			rv = rv.Addr()
+		}
+	}
+	t := rv.Type()
+	if !t.Implements(valueMarshalerType) && !t.Implements(marshalerType) && !t.Implements(textMarshalerType) {
+		return nil, false, nil
+	}
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return value.Null, true, nil
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+	switch m := rv.Interface().(type) {
+	case ValueMarshaler:
+		v, err = m.MarshalJSONValue()
+	case Marshaler:
+		var b []byte
+		b, err = m.MarshalJSON()
+		if err == nil {
+			v, err = parse(b)
+		}
+	case encoding.TextMarshaler:
+		var text []byte
+		text, err = m.MarshalText()
+		v = value.String(text)
+	}
+	if err != nil {
+		return nil, true, &MarshalError{Path: path, Type: t, Err: err}
+	}
+	return v, true, nil
+}
+
+func parse(b []byte) (interface{}, error) {
+	tokens, err := lexer.NewLexer(string(b)).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewParser(*tokens).Execute()
+}
+
+func (e *Encoder) encodeArray(path pointer.Pointer, rv reflect.Value) (interface{}, error) {
+	array := make(value.Array, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v, err := e.encode(path.AppendIndex(i), rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		array[i] = v
+	}
+	return array, nil
+}
+
+func (e *Encoder) encodeMap(path pointer.Pointer, rv reflect.Value) (interface{}, error) {
+	object := make(value.Object, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := mapKey(path, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		v, err := e.encode(path.Append(k), iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		object[k] = v
+	}
+	return object, nil
+}
+
+func (e *Encoder) encodeStruct(path pointer.Pointer, rv reflect.Value) (interface{}, error) {
+	object := value.Object{}
+	for _, f := range structfield.Fields(rv.Type()) {
+		fv, ok := structfield.Value(rv, f, false)
+		if !ok || (f.OmitEmpty && structfield.IsEmpty(fv)) {
+			continue
+		}
+		v, err := e.encode(path.Append(f.Name), fv)
+		if err != nil {
+			return nil, err
+		}
+		if f.Quoted {
+			v = quote(v)
+		}
+		object[f.Name] = v
+	}
+	return object, nil
+}
+
+func mapKey(path pointer.Pointer, k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", &MarshalError{Path: path, Type: k.Type(), Err: err}
+		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", &MarshalError{Path: path, Type: k.Type(), Err: ErrUnsupportedType}
+}
+
+// quote は`string`オプションのために数値と真偽値と文字列をJSONのテキストにした文字列にする
+func quote(v interface{}) interface{} {
+	switch v.(type) {
+	case value.NumberInt, value.NumberFloat, value.Bool, value.String:
+		var buf bytes.Buffer
+		if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+			return v
+		}
+		return value.String(buf.String())
+	}
+	return v
+}
@@ -0,0 +1,267 @@
+package encoder
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/decoder"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(l))), nil
+}
+
+type Base struct {
+	ID int `json:"id"`
+}
+
+type Item struct {
+	Base
+	Name     string            `json:"name"`
+	Price    float64           `json:"price"`
+	Count    int               `json:"count,string"`
+	Note     string            `json:"note,omitempty"`
+	Tags     []string          `json:"tags"`
+	Data     []byte            `json:"data"`
+	Created  time.Time         `json:"created"`
+	Level    Level             `json:"level"`
+	Parent   *Item             `json:"parent"`
+	Attrs    map[string]int    `json:"attrs"`
+	ByLevel  map[Level]bool    `json:"by_level"`
+	Extra    interface{}       `json:"extra"`
+	Hidden   string            `json:"-"`
+	Untagged map[int][]float32 `json:",omitempty"`
+}
+
+func TestSuccessMarshal(t *testing.T) {
+	input := Item{
+		Base:    Base{ID: 1},
+		Name:    "\"quoted\" <tag>\n",
+		Price:   1.5,
+		Count:   3,
+		Tags:    []string{"a", "b"},
+		Data:    []byte("hello"),
+		Created: time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   2,
+		Attrs:   map[string]int{"z": 1, "a": 2},
+		ByLevel: map[Level]bool{1: true},
+		Extra:   []interface{}{nil, true, 1e21},
+		Hidden:  "secret",
+	}
+	got, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("failed to marshal %#v", err)
+	}
+	want := `{"attrs":{"a":2,"z":1},"by_level":{"*":true},"count":"3","created":"2022-05-01T12:00:00Z",` +
+		`"data":"aGVsbG8=","extra":[null,true,1e+21],"id":1,"level":"**","name":"\"quoted\" <tag>\n",` +
+		`"parent":null,"price":1.5,"tags":["a","b"]}`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessMarshalRoundTrip(t *testing.T) {
+	type record struct {
+		Name    string         `json:"name"`
+		Count   int            `json:"count,string"`
+		Data    []byte         `json:"data"`
+		Created time.Time      `json:"created"`
+		Parent  *record        `json:"parent,omitempty"`
+		Attrs   map[string]int `json:"attrs"`
+	}
+	input := record{
+		Name:    "root",
+		Count:   10,
+		Data:    []byte{0, 1, 2, 255},
+		Created: time.Date(2022, 5, 1, 12, 0, 0, 123, time.FixedZone("JST", 9*60*60)),
+		Parent:  &record{Name: "parent", Attrs: map[string]int{}},
+		Attrs:   map[string]int{"a": 1},
+	}
+	b, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("failed to marshal %#v", err)
+	}
+	var got record
+	if err := decoder.Unmarshal(b, &got); err != nil {
+		t.Fatalf("failed to unmarshal %s: %#v", b, err)
+	}
+	if diff := cmp.Diff(got, input, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessMarshalShared(t *testing.T) {
+	// 循環していなければ同じmapやスライスを何度参照してもよい
+	shared := map[string]interface{}{"x": []interface{}{1}}
+	got, err := Marshal([]interface{}{shared, shared, shared["x"], shared["x"]})
+	if err != nil {
+		t.Fatalf("failed to marshal %#v", err)
+	}
+	if want := `[{"x":[1]},{"x":[1]},[1],[1]]`; string(got) != want {
+		t.Fatalf("want %s, but got %s", want, got)
+	}
+}
+
+func TestFailedMarshal(t *testing.T) {
+	type cyclic struct {
+		Next *cyclic `json:"next"`
+	}
+	c := &cyclic{}
+	c.Next = c
+	m := map[string]interface{}{}
+	m["self"] = m
+	sl := []interface{}{1, nil}
+	sl[1] = sl
+
+	tests := []struct {
+		name  string
+		input interface{}
+		path  string
+		want  error
+	}{
+		{
+			name:  "NaN",
+			input: map[string]float64{"x": math.NaN()},
+			path:  "/x",
+			want:  ErrUnsupportedValue,
+		},
+		{
+			name:  "関数",
+			input: []interface{}{1, func() {}},
+			path:  "/1",
+			want:  ErrUnsupportedType,
+		},
+		{
+			name:  "循環参照",
+			input: c,
+			path:  "/next",
+			want:  ErrUnsupportedValue,
+		},
+		{
+			name:  "mapの循環参照",
+			input: map[string]interface{}{"a": m},
+			path:  "/a/self",
+			want:  ErrUnsupportedValue,
+		},
+		{
+			name:  "スライスの循環参照",
+			input: sl,
+			path:  "/1",
+			want:  ErrUnsupportedValue,
+		},
+		{
+			name:  "int64に収まらない数値",
+			input: uint64(math.MaxUint64),
+			path:  "",
+			want:  ErrUnsupportedValue,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Marshal(tt.input)
+			if got != nil {
+				t.Errorf("want error %v, but got result %s", tt.want, got)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			var me *MarshalError
+			if !errors.As(err, &me) || me.Path.String() != tt.path {
+				t.Fatalf("want error at %q, but got %v", tt.path, err)
+			}
+		})
+	}
+}
+
+// Money は"1200JPY"のような文字列で表す金額
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m Money) MarshalJSONValue() (interface{}, error) {
+	return value.String(strconv.FormatInt(m.Amount, 10) + m.Currency), nil
+}
+
+// Status はencoding/jsonのjson.Marshalerを実装した型
+type Status int
+
+func (s *Status) MarshalJSON() ([]byte, error) {
+	return []byte(`{"code": ` + strconv.Itoa(int(*s)) + `}`), nil
+}
+
+type Broken struct{}
+
+func (Broken) MarshalJSON() ([]byte, error) {
+	return []byte(`{`), nil
+}
+
+type Order struct {
+	Price    Money         `json:"price"`
+	Discount *Money        `json:"discount"`
+	Status   Status        `json:"status"`
+	Timeout  time.Duration `json:"timeout"`
+	Err      error         `json:"err"`
+}
+
+func TestSuccessMarshalHooks(t *testing.T) {
+	input := &Order{
+		Price:   Money{Amount: 1200, Currency: "JPY"},
+		Status:  2,
+		Timeout: 90 * time.Second,
+		Err:     errors.New("failed"),
+	}
+	got, err := Marshal(input,
+		WithMarshalFunc(func(d time.Duration) (interface{}, error) {
+			return value.String(d.String()), nil
+		}),
+		WithMarshalFunc(func(err error) (interface{}, error) {
+			return value.String(err.Error()), nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to marshal %#v", err)
+	}
+	want := `{"discount":null,"err":"failed","price":"1200JPY","status":{"code":2},"timeout":"1m30s"}`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedMarshalHooks(t *testing.T) {
+	_, err := Marshal(map[string]Broken{"b": {}})
+	var me *MarshalError
+	if !errors.As(err, &me) || me.Path.String() != "/b" {
+		t.Fatalf("want error at /b, but got %v", err)
+	}
+}
+
+func TestSuccessMarshalRaw(t *testing.T) {
+	input := map[string]interface{}{
+		"payload": value.Raw(`{"b" : 1,  "a": 2}`),
+	}
+	got, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("failed to marshal %#v", err)
+	}
+	want := `{"payload":{"b" : 1,  "a": 2}}`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+
+	_, err = Marshal(map[string]value.Raw{"x": "{"})
+	var me *MarshalError
+	if !errors.As(err, &me) || me.Path.String() != "/x" {
+		t.Fatalf("want error at /x, but got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/ndjson"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/printer"
+)
+
+// runFmt はJSONを整形して標準出力に書く
+// ファイルを指定しない場合は標準入力を読む
+// 成功すれば0、エラーの場合は2を返す
+// -linesは1行に1つの文書を書くので、インデントと入出力の形式のフラグとは組み合わせられない
+//
+//	json-go fmt [-indent S] [-jsonc | -json5] [-to-json5] [file]
+//	json-go fmt -lines [-skip-errors] [file]
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	indent := fs.String("indent", "", "インデントに使う文字列 (空なら改行しない)")
+	jsonc := fs.Bool("jsonc", false, "コメントと末尾のカンマを受け付ける。コメントは出力しない")
+	json5 := fs.Bool("json5", false, "JSON5として読む")
+	toJSON5 := fs.Bool("to-json5", false, "JSON5で出力する")
+	lines := fs.Bool("lines", false, "1行に1つの文書があるNDJSONとして読み、1行に1つずつ書く")
+	skip := fs.Bool("skip-errors", false, "-linesで読めない行を飛ばし、標準エラーに報告する")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: json-go fmt [flags] [file]")
+		return 2
+	}
+	if *lines && (*indent != "" || *jsonc || *json5 || *toJSON5) {
+		fmt.Fprintln(os.Stderr, "usage: json-go fmt -lines [-skip-errors] [file]: -lines cannot be combined with -indent, -jsonc, -json5 or -to-json5")
+		return 2
+	}
+	if *skip && !*lines {
+		fmt.Fprintln(os.Stderr, "usage: json-go fmt -lines [-skip-errors] [file]: -skip-errors requires -lines")
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	name := "<stdin>"
+	if fs.NArg() == 1 {
+		name = fs.Arg(0)
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer f.Close()
+		r = f
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	var err error
+	if *lines {
+		err = fmtLines(w, r, name, *skip)
+	} else {
+		lopts, popts := []lexer.Option{}, []parser.Option{}
+		if *jsonc {
+			lopts = append(lopts, lexer.WithJSONC())
+		}
+		if *json5 {
+			lopts = append(lopts, lexer.WithJSON5())
+			popts = append(popts, parser.WithJSON5())
+		}
+		opts := []printer.Option{printer.WithIndent(*indent)}
+		if *toJSON5 {
+			opts = append(opts, printer.WithJSON5())
+		}
+		err = fmtValue(w, r, name, lopts, popts, opts)
+	}
+	// エラーでもそれまでに読めた値は書き出す
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
+
+func fmtValue(w *bufio.Writer, r io.Reader, name string, lopts []lexer.Option, popts []parser.Option, opts []printer.Option) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	tokens, err := lexer.NewLexer(string(b), lopts...).Execute()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	v, err := parser.NewParser(*tokens, popts...).Execute()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := printer.NewPrinter(v, append(opts, printer.WithWriter(w))...).Execute(); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+func fmtLines(w *bufio.Writer, r io.Reader, name string, skip bool) error {
+	opts := []ndjson.Option{}
+	if skip {
+		opts = append(opts, ndjson.WithErrorPolicy(ndjson.Skip))
+	}
+	nr := ndjson.NewReader(r, opts...)
+	nw := ndjson.NewWriter(w)
+	for {
+		v, err := nr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := nw.Write(v); err != nil {
+			return err
+		}
+	}
+	for _, le := range nr.Errors() {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, le)
+	}
+	return nil
+}
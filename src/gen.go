@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sam8helloworld/json-go/gen"
+)
+
+// runGen はサンプルのJSONからコードを生成する
+// 成功すれば0、エラーの場合は2を返す
+//
+//	json-go gen go [-package NAME] [-type NAME] [-lines] sample.json...
+func runGen(args []string) int {
+	if len(args) == 0 || args[0] != "go" {
+		fmt.Fprintln(os.Stderr, "usage: json-go gen go [flags] sample.json...")
+		return 2
+	}
+	fs := flag.NewFlagSet("gen go", flag.ContinueOnError)
+	pkg := fs.String("package", "main", "生成するファイルのパッケージ名")
+	typeName := fs.String("type", "Root", "ルートの型名")
+	lines := fs.Bool("lines", false, "ファイルを1行に1つのサンプルがあるNDJSONとして読む")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: json-go gen go [flags] sample.json...")
+		return 2
+	}
+
+	samples := make([]interface{}, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		if *lines {
+			docs, err := loadLines(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			samples = append(samples, docs...)
+			continue
+		}
+		v, err := load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		samples = append(samples, v)
+	}
+	src, err := gen.NewGenerator(samples, gen.WithPackage(*pkg), gen.WithTypeName(*typeName)).Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if _, err := os.Stdout.Write(src); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,274 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrNoSample = errors.New("no sample documents")
+)
+
+// Generator はサンプルのJSONからGoの型定義を生成する
+// 複数のサンプルを渡すと形を統合し、一部のサンプルにしかないフィールドは省略可能、
+// nullになることがあるフィールドはポインタ、整数と小数が混ざる数値はfloat64にする
+type Generator struct {
+	samples  []interface{}
+	pkg      string
+	typeName string
+
+	structs []*structDef
+	names   map[string]bool
+}
+
+type Option func(*Generator)
+
+// WithPackage は生成するファイルのパッケージ名を指定する
+// 指定しない場合はmain
+func WithPackage(name string) Option {
+	return func(g *Generator) {
+		g.pkg = name
+	}
+}
+
+// WithTypeName はルートの型名を指定する
+// 指定しない場合はRoot
+func WithTypeName(name string) Option {
+	return func(g *Generator) {
+		g.typeName = name
+	}
+}
+
+func NewGenerator(samples []interface{}, opts ...Option) *Generator {
+	g := &Generator{
+		samples:  samples,
+		pkg:      "main",
+		typeName: "Root",
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+type structDef struct {
+	name   string
+	fields []fieldDef
+}
+
+type fieldDef struct {
+	name string
+	typ  string
+	tag  string
+}
+
+// Execute はgofmtで整形したGoのソースコードを返す
+func (g *Generator) Execute() ([]byte, error) {
+	if len(g.samples) == 0 {
+		return nil, ErrNoSample
+	}
+	root := &shape{}
+	for _, s := range g.samples {
+		root.merge(s)
+	}
+
+	g.structs = nil
+	g.names = map[string]bool{}
+	name := exportedName(g.typeName)
+	g.names[name] = true
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by json-go gen go. DO NOT EDIT.\n\npackage %s\n\n", g.pkg)
+	if root.kinds&^kindNull == kindObject && len(root.fields) > 0 {
+		// ルートのobjectはそのまま名前を付けた構造体にする
+		g.define(name, root)
+	} else {
+		fmt.Fprintf(&buf, "type %s %s\n\n", name, g.goType(root, name, ""))
+	}
+	for _, s := range g.structs {
+		fmt.Fprintf(&buf, "type %s struct {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&buf, "%s %s %s\n", f.name, f.typ, f.tag)
+		}
+		buf.WriteString("}\n\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// define は構造体の定義を追加する
+// 入れ子の構造体は親の後ろに並ぶ
+func (g *Generator) define(name string, s *shape) {
+	def := &structDef{name: name}
+	g.structs = append(g.structs, def)
+	used := map[string]bool{}
+	for _, k := range s.keys() {
+		f := s.fields[k]
+		fieldName := uniqueName(exportedName(k), used)
+		typ := g.goType(f, exportedName(k), name)
+		optional := f.count < s.objects
+		if (optional || f.kinds&kindNull != 0) && needsPointer(typ) {
+			typ = "*" + typ
+		}
+		opts := ""
+		if optional {
+			opts = ",omitempty"
+		}
+		def.fields = append(def.fields, fieldDef{
+			name: fieldName,
+			typ:  typ,
+			tag:  structTag(k + opts),
+		})
+	}
+}
+
+// goType はshapeに対応するGoの型を返す
+// hintは構造体に付ける名前の候補、parentは親の構造体の名前
+func (g *Generator) goType(s *shape, hint, parent string) string {
+	switch s.kinds &^ kindNull {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int64"
+	case kindFloat, kindInt | kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindArray:
+		if s.elem == nil {
+			return "[]interface{}"
+		}
+		elem := g.goType(s.elem, singular(hint), parent)
+		if s.elem.kinds&kindNull != 0 && needsPointer(elem) {
+			elem = "*" + elem
+		}
+		return "[]" + elem
+	case kindObject:
+		if len(s.fields) == 0 {
+			return "map[string]interface{}"
+		}
+		name := g.typeNameFor(hint, parent)
+		g.define(name, s)
+		return name
+	}
+	// 種類が混ざっている値とnullにしかならない値はどんな値でも入るようにする
+	return "interface{}"
+}
+
+// typeNameFor は他の型と重ならない構造体の名前を決める
+// 重なる場合は親の名前を前に付け、それでも重なる場合は番号を付ける
+func (g *Generator) typeNameFor(hint, parent string) string {
+	if hint == "" {
+		hint = "Item"
+	}
+	name := hint
+	if g.names[name] {
+		name = parent + hint
+	}
+	name = uniqueName(name, g.names)
+	return name
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func needsPointer(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "interface{}"
+}
+
+func structTag(s string) string {
+	tag := "json:" + strconv.Quote(s)
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// commonInitialisms は名前の中で全て大文字にする略語
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "QPS": true, "RAM": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true, "XSRF": true, "XSS": true,
+}
+
+// exportedName はJSONのキーをGoの公開された識別子にする
+// e.g.
+//
+//	user_id  -> UserID
+//	firstName -> FirstName
+//	2fa      -> X2fa
+//	名前     -> X名前
+func exportedName(key string) string {
+	var b strings.Builder
+	for _, word := range splitWords(key) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		rs := []rune(word)
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	// 大文字のない文字で始まると公開されないので、数字と同じように前に付ける
+	if r := []rune(name)[0]; !unicode.IsUpper(r) {
+		name = "X" + name
+	}
+	return name
+}
+
+// splitWords は区切り文字と小文字から大文字への変わり目で単語に分ける
+func splitWords(s string) []string {
+	words := []string{}
+	current := []rune{}
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = []rune{}
+		}
+	}
+	var prev rune
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// singular は配列の要素の型名のために複数形の名前を単数形にする
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Item"
+}
@@ -0,0 +1,161 @@
+package gen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+)
+
+func parse(t *testing.T, inputs ...string) []interface{} {
+	t.Helper()
+	samples := []interface{}{}
+	for _, input := range inputs {
+		tokens, err := lexer.NewLexer(input).Execute()
+		if err != nil {
+			t.Fatalf("failed to tokenize %#v", err)
+		}
+		v, err := parser.NewParser(*tokens).Execute()
+		if err != nil {
+			t.Fatalf("failed to parse %#v", err)
+		}
+		samples = append(samples, v)
+	}
+	return samples
+}
+
+func TestSuccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []string
+		opts   []Option
+		want   string
+	}{
+		{
+			name: "複数のサンプルを統合",
+			inputs: []string{
+				`{"user_id": 1, "name": "taro", "score": 1, "address": {"zip_code": "100"}, "tags": ["a"], "items": [{"sku": "x", "price": 100}]}`,
+				`{"user_id": 2, "name": null, "score": 2.5, "tags": [], "items": [{"sku": "y", "price": 1.5, "note": "n"}], "homeURL": "http://example.com"}`,
+			},
+			want: `// Code generated by json-go gen go. DO NOT EDIT.
+
+package main
+
+type Root struct {
+	Address *Address ` + "`" + `json:"address,omitempty"` + "`" + `
+	HomeURL *string  ` + "`" + `json:"homeURL,omitempty"` + "`" + `
+	Items   []Item   ` + "`" + `json:"items"` + "`" + `
+	Name    *string  ` + "`" + `json:"name"` + "`" + `
+	Score   float64  ` + "`" + `json:"score"` + "`" + `
+	Tags    []string ` + "`" + `json:"tags"` + "`" + `
+	UserID  int64    ` + "`" + `json:"user_id"` + "`" + `
+}
+
+type Address struct {
+	ZipCode string ` + "`" + `json:"zip_code"` + "`" + `
+}
+
+type Item struct {
+	Note  *string ` + "`" + `json:"note,omitempty"` + "`" + `
+	Price float64 ` + "`" + `json:"price"` + "`" + `
+	Sku   string  ` + "`" + `json:"sku"` + "`" + `
+}
+`,
+		},
+		{
+			name:   "ルートが配列",
+			inputs: []string{`[{"id": 1, "value": "a"}, {"id": 2, "value": 1}, null]`},
+			opts:   []Option{WithPackage("model"), WithTypeName("entries")},
+			want: `// Code generated by json-go gen go. DO NOT EDIT.
+
+package model
+
+type Entries []*Entry
+
+type Entry struct {
+	ID    int64       ` + "`" + `json:"id"` + "`" + `
+	Value interface{} ` + "`" + `json:"value"` + "`" + `
+}
+`,
+		},
+		{
+			name:   "型名が重なる",
+			inputs: []string{`{"data": {"data": {"x": true}}, "2fa": {}, "a-b": null}`},
+			want: `// Code generated by json-go gen go. DO NOT EDIT.
+
+package main
+
+type Root struct {
+	X2fa map[string]interface{} ` + "`" + `json:"2fa"` + "`" + `
+	AB   interface{}            ` + "`" + `json:"a-b"` + "`" + `
+	Data Data                   ` + "`" + `json:"data"` + "`" + `
+}
+
+type Data struct {
+	Data DataData ` + "`" + `json:"data"` + "`" + `
+}
+
+type DataData struct {
+	X bool ` + "`" + `json:"x"` + "`" + `
+}
+`,
+		},
+		{
+			name:   "大文字のない文字で始まるキー",
+			inputs: []string{`{"名前": "taro", "住所": {"市": "東京"}}`},
+			want: `// Code generated by json-go gen go. DO NOT EDIT.
+
+package main
+
+type Root struct {
+	X住所 X住所    ` + "`" + `json:"住所"` + "`" + `
+	X名前 string ` + "`" + `json:"名前"` + "`" + `
+}
+
+type X住所 struct {
+	X市 string ` + "`" + `json:"市"` + "`" + `
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NewGenerator(parse(t, tt.inputs...), tt.opts...).Execute()
+			if err != nil {
+				t.Fatalf("failed to generate %#v", err)
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailed(t *testing.T) {
+	if _, err := NewGenerator(nil).Execute(); !errors.Is(err, ErrNoSample) {
+		t.Fatalf("want ErrNoSample, but got %v", err)
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"user_id":    "UserID",
+		"firstName":  "FirstName",
+		"api-url":    "APIURL",
+		"2fa":        "X2fa",
+		"":           "Field",
+		"HTTPStatus": "HTTPStatus",
+		"v1Items":    "V1Items",
+		"名前":         "X名前",
+		"ユーザー_id":    "XユーザーID",
+	}
+	for input, want := range tests {
+		if got := exportedName(input); got != want {
+			t.Errorf("%q: want %s, but got %s", input, want, got)
+		}
+	}
+}
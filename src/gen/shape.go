@@ -0,0 +1,80 @@
+package gen
+
+import (
+	"sort"
+
+	"github.com/sam8helloworld/json-go/value"
+)
+
+// kind はある位置に現れた値の種類の集合
+type kind int
+
+const (
+	kindNull kind = 1 << iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindArray
+	kindObject
+)
+
+// shape はサンプル中の同じ位置に現れた値をまとめたもの
+type shape struct {
+	kinds kind
+	// count はこの位置に値が現れた回数
+	count int
+	// objects は値がobjectだった回数
+	objects int
+	fields  map[string]*shape
+	// elem は配列の要素をまとめたもの。要素が1つもなければnil
+	elem *shape
+}
+
+func (s *shape) merge(v interface{}) {
+	s.count++
+	switch val := v.(type) {
+	case value.Bool:
+		s.kinds |= kindBool
+	case value.NumberInt:
+		s.kinds |= kindInt
+	case value.NumberFloat:
+		s.kinds |= kindFloat
+	case value.String:
+		s.kinds |= kindString
+	case value.Array:
+		s.kinds |= kindArray
+		for _, vi := range val {
+			if s.elem == nil {
+				s.elem = &shape{}
+			}
+			s.elem.merge(vi)
+		}
+	case value.Object:
+		s.kinds |= kindObject
+		s.objects++
+		if s.fields == nil {
+			s.fields = map[string]*shape{}
+		}
+		for k, vi := range val {
+			f, ok := s.fields[k]
+			if !ok {
+				f = &shape{}
+				s.fields[k] = f
+			}
+			f.merge(vi)
+		}
+	default:
+		s.kinds |= kindNull
+	}
+}
+
+// keys はフィールドのキーをソートして返す
+func (s *shape) keys() []string {
+	keys := make([]string, 0, len(s.fields))
+	for k := range s.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,234 @@
+package structfield
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field はJSONのキーに対応する構造体のフィールド
+type Field struct {
+	// Name はJSONのキー
+	Name string
+	// Index はreflect.Value.FieldByIndexに渡す添字の列
+	// 埋め込み構造体のフィールドは複数の添字になる
+	Index []int
+	Type  reflect.Type
+	// OmitEmpty はタグに`omitempty`が指定されているか
+	OmitEmpty bool
+	// Quoted はタグに`string`が指定されているか
+	Quoted bool
+	// Required はタグに`required`が指定されているか
+	Required bool
+
+	tagged bool
+}
+
+var cache sync.Map // map[reflect.Type][]Field
+
+// Fields は構造体のフィールドを`json`タグに従って列挙する
+// 埋め込み構造体のフィールドはencoding/jsonと同じ規則で昇格させる
+func Fields(t reflect.Type) []Field {
+	if fs, ok := cache.Load(t); ok {
+		return fs.([]Field)
+	}
+	fs, _ := cache.LoadOrStore(t, typeFields(t))
+	return fs.([]Field)
+}
+
+// Lookup はnameに一致するフィールドを探す
+// 完全に一致するものがなければ大文字小文字を区別せずに探す
+func Lookup(fields []Field, name string) (Field, bool) {
+	if f, ok := LookupExact(fields, name); ok {
+		return f, true
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// LookupExact はnameに完全に一致するフィールドだけを探す
+func LookupExact(fields []Field, name string) (Field, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func typeFields(t reflect.Type) []Field {
+	type entry struct {
+		typ   reflect.Type
+		index []int
+	}
+	// 浅い階層から順に幅優先で探索する
+	current := []entry{}
+	next := []entry{{typ: t}}
+	visited := map[reflect.Type]bool{}
+	fields := []Field{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		depthFields := []Field{}
+		for _, e := range current {
+			// 浅い階層で探索済みの型は無視する
+			if visited[e.typ] {
+				continue
+			}
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts := parseTag(tag)
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					// 名前を指定していない埋め込み構造体はフィールドを昇格させる
+					if name == "" && ft.Kind() == reflect.Struct {
+						next = append(next, entry{typ: ft, index: index})
+						continue
+					}
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				f := Field{
+					Name:      name,
+					Index:     index,
+					Type:      sf.Type,
+					OmitEmpty: opts.contains("omitempty"),
+					Quoted:    opts.contains("string"),
+					Required:  opts.contains("required"),
+					tagged:    name != "",
+				}
+				if f.Name == "" {
+					f.Name = sf.Name
+				}
+				depthFields = append(depthFields, f)
+			}
+		}
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+		fields = append(fields, dominant(fields, depthFields)...)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return lessIndex(fields[i].Index, fields[j].Index)
+	})
+	return fields
+}
+
+// dominant は同じ階層で同じ名前のフィールドから採用するものを選ぶ
+// より浅い階層に同じ名前があれば採用せず、タグ付きのものが1つだけならそれを採用する
+func dominant(shallower, fields []Field) []Field {
+	exists := map[string]bool{}
+	for _, f := range shallower {
+		exists[f.Name] = true
+	}
+	byName := map[string][]Field{}
+	names := []string{}
+	for _, f := range fields {
+		if exists[f.Name] {
+			continue
+		}
+		if _, ok := byName[f.Name]; !ok {
+			names = append(names, f.Name)
+		}
+		byName[f.Name] = append(byName[f.Name], f)
+	}
+	result := []Field{}
+	for _, name := range names {
+		fs := byName[name]
+		if len(fs) == 1 {
+			result = append(result, fs[0])
+			continue
+		}
+		tagged := []Field{}
+		for _, f := range fs {
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			result = append(result, tagged[0])
+		}
+	}
+	return result
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+type tagOptions []string
+
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	return parts[0], tagOptions(parts[1:])
+}
+
+func (o tagOptions) contains(name string) bool {
+	for _, opt := range o {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty は`omitempty`で省略される値かどうかを返す
+func IsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Value は構造体vからフィールドの値を取り出す
+// 埋め込み構造体のポインタがnilの場合、allocがtrueなら割り当て、falseなら見つからなかったとしてfalseを返す
+func Value(v reflect.Value, f Field, alloc bool) (reflect.Value, bool) {
+	for i, x := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,76 @@
+package structfield
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type Inner struct {
+	A string
+	B string `json:"b,omitempty"`
+}
+
+type Other struct {
+	A string
+	C int
+}
+
+type outer struct {
+	Inner
+	*Other
+	D       bool   `json:"d,string,required"`
+	Ignored string `json:"-"`
+	hidden  string
+	C       int `json:"C"`
+}
+
+func TestFields(t *testing.T) {
+	got := Fields(reflect.TypeOf(outer{}))
+	want := []Field{
+		// InnerとOtherのAは同じ階層でタグもないので両方とも採用しない
+		{Name: "b", Index: []int{0, 1}, Type: reflect.TypeOf(""), OmitEmpty: true, tagged: true},
+		{Name: "d", Index: []int{2}, Type: reflect.TypeOf(true), Quoted: true, Required: true, tagged: true},
+		// Other.Cより浅い階層にあるCを採用する
+		{Name: "C", Index: []int{5}, Type: reflect.TypeOf(0), tagged: true},
+	}
+	if diff := cmp.Diff(got, want, cmp.AllowUnexported(Field{}), cmpopts.IgnoreFields(Field{}, "Type")); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	fields := []Field{{Name: "name"}, {Name: "Name"}, {Name: "id"}}
+	if f, ok := Lookup(fields, "Name"); !ok || f.Name != "Name" {
+		t.Errorf("want exact match, but got %v", f)
+	}
+	if f, ok := Lookup(fields, "ID"); !ok || f.Name != "id" {
+		t.Errorf("want case insensitive match, but got %v", f)
+	}
+	if _, ok := Lookup(fields, "x"); ok {
+		t.Errorf("want no match")
+	}
+	if _, ok := LookupExact(fields, "ID"); ok {
+		t.Errorf("want no case insensitive match")
+	}
+}
+
+func TestValue(t *testing.T) {
+	type Embedded struct{ X int }
+	type s struct{ *Embedded }
+	f := Fields(reflect.TypeOf(s{}))[0]
+	v := reflect.ValueOf(&s{}).Elem()
+	if _, ok := Value(v, f, false); ok {
+		t.Errorf("want not found through nil pointer")
+	}
+	fv, ok := Value(v, f, true)
+	if !ok {
+		t.Fatalf("want allocated")
+	}
+	fv.SetInt(1)
+	if v.Interface().(s).X != 1 {
+		t.Errorf("value was not set")
+	}
+}
@@ -0,0 +1,135 @@
+package jsonseq
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/token"
+)
+
+var (
+	ErrMissingSeparator = errors.New("record does not start with RS")
+)
+
+// RecordError は何番目のレコードが読めなかったかを表す
+// レコードは1から数え、最初のRSより前の部分は0番目になる
+type RecordError struct {
+	Record int
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader はRSで区切られたJSON text sequence (RFC 7464, application/json-seq) を1レコードずつ読む
+// 読めないレコードがあっても次のRSから読み直せるので、途中で切れたレコードは*RecordErrorを返して次のレコードに進む
+// 空のレコードは無視する
+type Reader struct {
+	lexer *lexer.Lexer
+	// record はこれまでに読んだRSの数
+	record int
+	// pending は次のレコードのRSを読んだが、まだ数えていないこと
+	pending bool
+	last    int
+	err     error
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		lexer: lexer.NewReaderLexer(r, lexer.WithRecordSeparator()),
+	}
+}
+
+// Next は次のレコードの値をパースして返す
+// 読み終えたらio.EOFを返す。読めないレコードのエラーは*RecordErrorで、続けてNextを呼ぶと次のレコードを読む
+func (r *Reader) Next() (interface{}, error) {
+	for r.err == nil {
+		tokens, err := r.collect()
+		r.last = r.record
+		if err != nil {
+			// レコードの残りを捨てて次のRSから読み直す
+			if serr := r.lexer.SkipRecord(); serr != nil {
+				r.err = serr
+				return nil, serr
+			}
+			return nil, &RecordError{Record: r.last, Err: err}
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if r.last == 0 {
+			return nil, &RecordError{Record: r.last, Err: ErrMissingSeparator}
+		}
+		v, err := parser.NewParser(tokens).Execute()
+		if err != nil {
+			return nil, &RecordError{Record: r.last, Err: err}
+		}
+		return v, nil
+	}
+	return nil, r.err
+}
+
+// Record は最後に読んだレコードの番号を返す
+func (r *Reader) Record() int {
+	return r.last
+}
+
+// collect は次のRSか入力の終わりまでのトークンを集める
+func (r *Reader) collect() ([]token.Token, error) {
+	if r.pending {
+		r.record++
+		r.pending = false
+	}
+	tokens := []token.Token{}
+	for {
+		t, err := r.lexer.Next()
+		if err == io.EOF {
+			r.err = io.EOF
+			return tokens, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.(token.RecordSeparatorToken); ok {
+			if len(tokens) > 0 {
+				r.pending = true
+				return tokens, nil
+			}
+			r.record++
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// Writer は値をRSで始まりLFで終わる1つのレコードにして書き出す
+type Writer struct {
+	writer io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		writer: w,
+	}
+}
+
+func (w *Writer) Write(v interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteRune(lexer.RecordSeparatorSymbol)
+	if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := w.writer.Write(buf.Bytes())
+	return err
+}
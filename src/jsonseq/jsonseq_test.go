@@ -0,0 +1,105 @@
+package jsonseq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+func TestSuccessReader(t *testing.T) {
+	input := "\x1e{\"a\": 1}\n\x1e\x1e[true, null]\n\x1e\"x\"\n"
+	r := NewReader(strings.NewReader(input))
+	got := []interface{}{}
+	records := []int{}
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read %#v", err)
+		}
+		got = append(got, v)
+		records = append(records, r.Record())
+	}
+	want := []interface{}{
+		value.Object{"a": value.NumberInt(1)},
+		value.Array{value.Bool(true), value.Null},
+		value.String("x"),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if diff := cmp.Diff(records, []int{1, 3, 4}); diff != "" {
+		t.Fatalf("record numbers differ: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedReader(t *testing.T) {
+	// 途中で切れたレコードは飛ばして次のレコードから読み直す
+	input := "[0]\n\x1e{\"a\": [1, tr\x1e12\x1e{\"b\": \x1e\"ok\"\n\x1e3"
+	r := NewReader(strings.NewReader(input))
+	type result struct {
+		Value  interface{}
+		Record int
+		Err    error
+	}
+	got := []result{}
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		res := result{Value: v, Record: r.Record()}
+		var re *RecordError
+		if errors.As(err, &re) {
+			res.Record, res.Err = re.Record, re.Err
+		} else if err != nil {
+			t.Fatalf("unexpected error %#v", err)
+		}
+		got = append(got, res)
+	}
+	want := []result{
+		{Record: 0, Err: ErrMissingSeparator},
+		{Record: 1, Err: lexer.ErrBoolTokenize},
+		{Record: 2, Err: lexer.ErrTruncated},
+		{Record: 3, Err: parser.ErrParse},
+		{Record: 4, Value: value.String("ok")},
+		{Record: 5, Err: lexer.ErrTruncated},
+	}
+	if diff := cmp.Diff(got, want, cmp.Comparer(func(x, y error) bool { return errors.Is(x, y) })); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, v := range []interface{}{value.Object{"a": value.Array{value.NumberInt(1)}}, value.NumberInt(2)} {
+		if err := w.Write(v); err != nil {
+			t.Fatalf("failed to write %#v", err)
+		}
+	}
+	want := "\x1e{\"a\":[1]}\n\x1e2\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("want %q, but got %q", want, got)
+	}
+
+	// 書き出したものは読み直せる
+	r := NewReader(&buf)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Next(); err != nil {
+			t.Fatalf("failed to read %#v", err)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("want io.EOF, but got %v", err)
+	}
+}
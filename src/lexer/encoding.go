@@ -0,0 +1,214 @@
+package lexer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding は入力の文字エンコーディング
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF16LE
+	UTF16BE
+	UTF32LE
+	UTF32BE
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case UTF32LE:
+		return "UTF-32LE"
+	case UTF32BE:
+		return "UTF-32BE"
+	}
+	return "UTF-8"
+}
+
+// WithStrictUTF8 はRFC 8259 8.1のとおり入力をBOMのないUTF-8に限る
+// BOM、UTF-16とUTF-32の入力、不正なUTF-8のバイト列はErrEncodingにする
+// 指定しない場合はBOMを読み飛ばし、UTF-16とUTF-32の入力はUTF-8と同じように読む
+func WithStrictUTF8() Option {
+	return func(l *Lexer) {
+		l.strictUTF8 = true
+	}
+}
+
+// Encoding は入力の先頭から判定したエンコーディングを返す
+func (l *Lexer) Encoding() Encoding {
+	return l.encoding
+}
+
+// BOM は入力の先頭にBOMがあったことを返す
+// BOMは読み飛ばすので、InputとSpansには含まれない
+func (l *Lexer) BOM() bool {
+	return l.bom
+}
+
+// detectEncoding は入力の先頭のバイトからエンコーディングを判定し、BOMのバイト数を返す
+// BOMがない場合はRFC 4627 3のとおり、最初の文字がASCIIであることを使って0のバイトの並びから判定する
+func detectEncoding(b []byte) (Encoding, int) {
+	switch {
+	case len(b) >= 3 && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF:
+		return UTF8, 3
+	case len(b) >= 4 && b[0] == 0x00 && b[1] == 0x00 && b[2] == 0xFE && b[3] == 0xFF:
+		return UTF32BE, 4
+	case len(b) >= 4 && b[0] == 0xFF && b[1] == 0xFE && b[2] == 0x00 && b[3] == 0x00:
+		return UTF32LE, 4
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		return UTF16BE, 2
+	case len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE:
+		return UTF16LE, 2
+	case len(b) >= 4 && b[0] == 0x00 && b[1] == 0x00 && b[2] == 0x00 && b[3] != 0x00:
+		return UTF32BE, 0
+	case len(b) >= 4 && b[0] != 0x00 && b[1] == 0x00 && b[2] == 0x00 && b[3] == 0x00:
+		return UTF32LE, 0
+	case len(b) >= 2 && b[0] == 0x00 && b[1] != 0x00:
+		return UTF16BE, 0
+	case len(b) >= 2 && b[0] != 0x00 && b[1] == 0x00:
+		return UTF16LE, 0
+	}
+	return UTF8, 0
+}
+
+// newRuneReader はrの先頭でエンコーディングを判定し、UTF-8にして1文字ずつ読むruneReaderを作る
+func (l *Lexer) newRuneReader(r io.Reader) (*runeReader, error) {
+	br := bufio.NewReader(r)
+	// 4バイトに満たない入力はそのまま判定する
+	b, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	enc, n := detectEncoding(b)
+	if l.strictUTF8 && (enc != UTF8 || n > 0) {
+		return nil, ErrEncoding
+	}
+	if _, err := br.Discard(n); err != nil {
+		return nil, err
+	}
+	l.encoding = enc
+	l.bom = n > 0
+	return &runeReader{reader: br, encoding: enc, strict: l.strictUTF8}, nil
+}
+
+// decodeString はinputをエンコーディングに応じてInputにする
+func (l *Lexer) decodeString(input string) ([]rune, error) {
+	prefix := input
+	if len(prefix) > 4 {
+		prefix = prefix[:4]
+	}
+	if enc, n := detectEncoding([]byte(prefix)); enc == UTF8 {
+		// UTF-8はio.Readerを介さずにそのまま変換する
+		if l.strictUTF8 && (n > 0 || !utf8.ValidString(input)) {
+			return nil, ErrEncoding
+		}
+		l.bom = n > 0
+		return []rune(input[n:]), nil
+	}
+	rr, err := l.newRuneReader(strings.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune{}
+	for {
+		r, _, err := rr.ReadRune()
+		if err == io.EOF {
+			return runes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+}
+
+// runeReader は入力をencodingで1文字ずつ読む
+// 一度エラーになったら、それ以降も同じエラーを返す
+type runeReader struct {
+	reader   *bufio.Reader
+	encoding Encoding
+	strict   bool
+	err      error
+}
+
+func (r *runeReader) ReadRune() (rune, int, error) {
+	if r.err != nil {
+		return 0, 0, r.err
+	}
+	ch, size, err := r.readRune()
+	if err != nil {
+		r.err = err
+		return 0, 0, err
+	}
+	return ch, size, nil
+}
+
+func (r *runeReader) readRune() (rune, int, error) {
+	switch r.encoding {
+	case UTF16LE, UTF16BE:
+		u, err := r.readUnit(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !utf16.IsSurrogate(rune(u)) {
+			return rune(u), 2, nil
+		}
+		// 上位サロゲートの後ろが下位サロゲートなら2つで1文字
+		b, err := r.reader.Peek(2)
+		if err != nil {
+			return utf8.RuneError, 2, nil
+		}
+		ch := utf16.DecodeRune(rune(u), rune(r.order().Uint16(b)))
+		if ch == utf8.RuneError {
+			return ch, 2, nil
+		}
+		r.reader.Discard(2)
+		return ch, 4, nil
+	case UTF32LE, UTF32BE:
+		u, err := r.readUnit(4)
+		if err != nil {
+			return 0, 0, err
+		}
+		if ch := rune(u); utf8.ValidRune(ch) {
+			return ch, 4, nil
+		}
+		return utf8.RuneError, 4, nil
+	}
+	ch, size, err := r.reader.ReadRune()
+	if err == nil && r.strict && ch == utf8.RuneError && size == 1 {
+		return 0, 0, ErrEncoding
+	}
+	return ch, size, err
+}
+
+// readUnit はUTF-16かUTF-32の1単位を読む
+// 入力が単位の途中で終わっていたらErrEncodingを返す
+func (r *runeReader) readUnit(n int) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r.reader, b[:n]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, ErrEncoding
+		}
+		return 0, err
+	}
+	if n == 2 {
+		return uint32(r.order().Uint16(b[:2])), nil
+	}
+	return r.order().Uint32(b[:]), nil
+}
+
+func (r *runeReader) order() binary.ByteOrder {
+	if r.encoding == UTF16LE || r.encoding == UTF32LE {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
@@ -0,0 +1,138 @@
+package lexer
+
+import (
+	"strconv"
+	"unicode"
+
+	"github.com/sam8helloworld/json-go/token"
+)
+
+const SingleQuoteSymbol = rune('\'')
+
+// WithJSON5 はJSON5 (https://spec.json5.org/) の入力を受け付ける
+// WithJSONCのコメントと末尾のカンマに加えて、クォートしていないキー、シングルクォートの文字列、
+// 16進数、Infinity、NaN、先頭と末尾の小数点、\で改行した複数行の文字列を読む
+// キーとInfinity、NaNはtoken.IdentifierTokenになるので、parser.WithJSON5と一緒に使う
+func WithJSON5() Option {
+	return func(l *Lexer) {
+		l.jsonc = true
+		l.json5 = true
+	}
+}
+
+// identifierTokenize はクォートしていない識別子を読む
+// true、false、nullはそれぞれのトークンにする
+func (l *Lexer) identifierTokenize() (token.Token, error) {
+	switch word := l.readIdentifier(); word {
+	case "true":
+		return token.TrueToken{}, nil
+	case "false":
+		return token.FalseToken{}, nil
+	case "null":
+		return token.NullToken{}, nil
+	default:
+		return token.NewIdentifierToken(word), nil
+	}
+}
+
+// readIdentifier はl.Chから識別子の終わりまでを読む
+func (l *Lexer) readIdentifier() string {
+	word := []rune{l.Ch}
+	for isIdentifierPart(l.peakChar()) {
+		word = append(word, l.readChar())
+	}
+	return string(word)
+}
+
+// json5NumberTokenize はJSONの数値に加えて、16進数と符号付きのInfinity、NaNを読む
+func (l *Lexer) json5NumberTokenize() (token.Token, error) {
+	num := string(l.Ch)
+	if l.Ch == NumberPlusSymbol || l.Ch == NumberMinusSymbol {
+		switch l.peakChar() {
+		case 'I', 'N':
+			l.readChar()
+			word := l.readIdentifier()
+			if word != "Infinity" && word != "NaN" {
+				return nil, ErrLexer
+			}
+			return token.NewNumberToken(num + word), nil
+		case '0':
+			num += string(l.readChar())
+		}
+	}
+	if l.Ch == '0' {
+		if ch := l.peakChar(); ch == 'x' || ch == 'X' {
+			num += string(l.readChar())
+			for isAsciiHexdigit(l.peakChar()) {
+				num += string(l.readChar())
+			}
+			return token.NewNumberToken(num), nil
+		}
+	}
+	for isNumberSymbol(l.peakChar()) {
+		if l.maxStringLength > 0 && len(num) >= l.maxStringLength {
+			return nil, ErrStringTooLong
+		}
+		num += string(l.readChar())
+	}
+	return token.NewNumberToken(num), nil
+}
+
+// json5Escape はJSONにないJSON5のエスケープを読む
+// 改行をエスケープした場合は文字列を次の行に続けるので、addをfalseで返す
+// それ以外の文字はその文字自身になり、数字はエスケープできないのでokをfalseで返す
+func (l *Lexer) json5Escape(ch rune) (r rune, add bool, ok bool) {
+	switch ch {
+	case 'v':
+		return '\v', true, true
+	case '0':
+		// \0の後ろに数字は続けられない
+		if c := l.peakChar(); '0' <= c && c <= '9' {
+			return 0, false, false
+		}
+		return 0, true, true
+	case 'x':
+		hexString := ""
+		for i := 0; i < 2; i++ {
+			if !isAsciiHexdigit(l.peakChar()) {
+				return 0, false, false
+			}
+			hexString += string(l.readChar())
+		}
+		hex, err := strconv.ParseUint(hexString, 16, 8)
+		if err != nil {
+			return 0, false, false
+		}
+		return rune(hex), true, true
+	case WhiteSpaceCRSymbol:
+		// CRLFは1つの改行
+		if l.peakChar() == WhiteSpaceLFSymbol {
+			l.readChar()
+		}
+		return 0, false, true
+	case WhiteSpaceLFSymbol, '\u2028', '\u2029':
+		return 0, false, true
+	}
+	if ch == 0 || ('1' <= ch && ch <= '9') {
+		return 0, false, false
+	}
+	return ch, true, true
+}
+
+// isJSON5Space はJSONの空白に加えてJSON5で空白として扱う文字か判定する
+func isJSON5Space(ch rune) bool {
+	switch ch {
+	case '\v', '\f', '\u00A0', '\u2028', '\u2029', '\uFEFF':
+		return true
+	}
+	return unicode.Is(unicode.Zs, ch)
+}
+
+func isIdentifierStart(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '$' || ch == '_'
+}
+
+func isIdentifierPart(ch rune) bool {
+	return isIdentifierStart(ch) || unicode.IsDigit(ch) ||
+		unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Pc) || ch == '\u200C' || ch == '\u200D'
+}
@@ -2,8 +2,10 @@ package lexer
 
 import (
 	"errors"
+	"io"
 	"strconv"
 	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/sam8helloworld/json-go/token"
 )
@@ -14,6 +16,11 @@ var (
 	ErrNullTokenize   = errors.New("failed to null tokenize")
 	ErrStringToHex    = errors.New("failed to string to hex")
 	ErrLexer          = errors.New("failed to lexer")
+	ErrTruncated      = errors.New("truncated record")
+	ErrComment        = errors.New("failed to comment tokenize")
+	ErrInputTooLarge  = errors.New("input too large")
+	ErrStringTooLong  = errors.New("string too long")
+	ErrEncoding       = errors.New("invalid encoding")
 )
 
 const (
@@ -44,88 +51,357 @@ const (
 	TabSymbol           = rune('t')
 )
 
+// RecordSeparatorSymbol はJSON text sequenceのレコードの区切り
+const RecordSeparatorSymbol = rune(0x1E)
+
 type Lexer struct {
 	Input        []rune
 	Position     int  // 読み込んでる文字のインデックス
 	ReadPosition int  // 次に読み込む文字のインデックス
 	Ch           rune // 検査中の文字
+
+	spans  []token.Span
+	span   token.Span
+	reader io.RuneReader
+	peeked []rune
+	eof    bool
+	err    error
+
+	recordSeparator bool
+	inToken         bool
+
+	jsonc         bool
+	commentTokens bool
+	// pending はJSONCで末尾のカンマか確かめるために先読みしたトークン
+	pending []pendingToken
+	last    token.Token
+
+	json5 bool
+
+	maxInputSize    int
+	maxStringLength int
+	// size はこれまでに読んだ入力のバイト数
+	size int
+
+	strictUTF8 bool
+	encoding   Encoding
+	bom        bool
+}
+
+// 信頼できない入力 (HTTPのリクエストなど) を読む場合の上限の目安
+const (
+	SafeMaxInputSize    = 10 << 20
+	SafeMaxStringLength = 1 << 20
+)
+
+type pendingToken struct {
+	token token.Token
+	span  token.Span
+}
+
+type Option func(*Lexer)
+
+// WithRecordSeparator はRS (0x1E) をJSON text sequence (RFC 7464) のレコードの区切りとして
+// token.RecordSeparatorTokenにする
+// RSはトークンの途中でも区切りとして扱い、トークンの一部としては読まない
+// 数値とtrue、false、nullの直後がRSか入力の終わりの場合は、途中で切れている可能性があるのでErrTruncatedを返す
+func WithRecordSeparator() Option {
+	return func(l *Lexer) {
+		l.recordSeparator = true
+	}
+}
+
+// WithJSONC は//と/* */のコメントと、配列とobjectの末尾のカンマを受け付ける
+// コメントは空白と同じく読み飛ばし、末尾のカンマはトークンにしない
+func WithJSONC() Option {
+	return func(l *Lexer) {
+		l.jsonc = true
+	}
 }
 
-func NewLexer(input string) *Lexer {
+// WithCommentTokens はWithJSONCのコメントを読み飛ばさずにtoken.CommentTokenにする
+// コメントを残したいツールのためのもので、WithJSONCも有効になる
+func WithCommentTokens() Option {
+	return func(l *Lexer) {
+		l.jsonc = true
+		l.commentTokens = true
+	}
+}
+
+// WithMaxInputSize は読む入力の大きさの上限をバイト数で指定する
+// 超えるとErrInputTooLargeを返す。指定しないか0の場合は上限なし
+func WithMaxInputSize(n int) Option {
+	return func(l *Lexer) {
+		l.maxInputSize = n
+	}
+}
+
+// WithMaxStringLength は1つの文字列と数値の長さの上限をrune数で指定する
+// 超えるとErrStringTooLongを返す。指定しないか0の場合は上限なし
+func WithMaxStringLength(n int) Option {
+	return func(l *Lexer) {
+		l.maxStringLength = n
+	}
+}
+
+// WithSafeLimits は信頼できない入力を読むための上限をまとめて指定する
+// 入力はSafeMaxInputSize、文字列と数値はSafeMaxStringLengthまで
+func WithSafeLimits() Option {
+	return func(l *Lexer) {
+		l.maxInputSize = SafeMaxInputSize
+		l.maxStringLength = SafeMaxStringLength
+	}
+}
+
+// NewLexer はinputを読むLexerを作る
+// inputの先頭のBOMは読み飛ばし、UTF-16とUTF-32の場合はUTF-8にしてInputにする
+// inputが読めないエンコーディングの場合はNextがErrEncodingを返す
+func NewLexer(input string, opts ...Option) *Lexer {
+	l := &Lexer{}
+	for _, opt := range opts {
+		opt(l)
+	}
 	// Lexerに引数inputをセットしreturn
-	return &Lexer{Input: []rune(input)}
+	l.Input, l.err = l.decodeString(input)
+	return l
+}
+
+// NewReaderLexer はrから少しずつ読みながらトークンにするLexerを作る
+// 入力全体をメモリに載せないので、Inputは空のままになる
+func NewReaderLexer(r io.Reader, opts ...Option) *Lexer {
+	l := &Lexer{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	rr, err := l.newRuneReader(r)
+	if err != nil {
+		// readerもInputもないので、Nextはすぐにこのエラーを返す
+		l.err = err
+		return l
+	}
+	l.reader = rr
+	return l
 }
 
 func (l *Lexer) Execute() (*[]token.Token, error) {
 	// 1文字ずつ読み取ってその文字によってどのパースを行うか分岐
 	// パースしてトークンを返す
 	tokens := []token.Token{}
-	for ch := l.readChar(); l.ReadPosition <= len(l.Input); ch = l.readChar() {
-		switch {
-		case ch == LeftBraceSymbol:
-			tokens = append(tokens, token.LeftBraceToken{})
-		case ch == RightBraceSymbol:
-			tokens = append(tokens, token.RightBraceToken{})
-		case ch == LeftBracketSymbol:
-			tokens = append(tokens, token.LeftBracketToken{})
-		case ch == RightBracketSymbol:
-			tokens = append(tokens, token.RightBracketToken{})
-		case ch == ColonSymbol:
-			tokens = append(tokens, token.ColonToken{})
-		case ch == CommaSymbol:
-			tokens = append(tokens, token.CommaToken{})
-		case ch == TrueSymbol:
-			token, err := l.boolTokenize(true)
-			if err != nil {
-				return nil, err
-			}
-			tokens = append(tokens, token)
-		case ch == FalseSymbol:
-			token, err := l.boolTokenize(false)
-			if err != nil {
-				return nil, err
+	l.spans = []token.Span{}
+	for {
+		t, err := l.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		l.spans = append(l.spans, l.span)
+	}
+	return &tokens, nil
+}
+
+// Next は次のトークンを1つ読んで返す
+// 入力を読み終えたらio.EOFを返す
+func (l *Lexer) Next() (token.Token, error) {
+	t, err := l.nextToken()
+	if err != nil || !l.jsonc {
+		return t, err
+	}
+	if _, ok := t.(token.CommentToken); ok {
+		return t, nil
+	}
+	last := l.last
+	l.last = t
+	if _, ok := t.(token.CommaToken); !ok {
+		return t, nil
+	}
+	switch last.(type) {
+	case token.LeftBraceToken, token.LeftBracketToken, token.CommaToken:
+		return t, nil
+	}
+	// 次のコメントでないトークンが閉じ括弧なら末尾のカンマなので捨てる
+	comma := l.span
+	for {
+		nt, err := l.readToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		l.pending = append(l.pending, pendingToken{token: nt, span: l.span})
+		if _, ok := nt.(token.CommentToken); !ok {
+			break
+		}
+	}
+	if n := len(l.pending); n > 0 {
+		switch l.pending[n-1].token.(type) {
+		case token.RightBraceToken, token.RightBracketToken:
+			return l.Next()
+		}
+	}
+	l.span = comma
+	return t, nil
+}
+
+// nextToken は先読みしたトークンがあればそれを、なければ入力から次のトークンを読む
+func (l *Lexer) nextToken() (token.Token, error) {
+	if len(l.pending) > 0 {
+		p := l.pending[0]
+		l.pending = l.pending[1:]
+		l.span = p.span
+		return p.token, nil
+	}
+	return l.readToken()
+}
+
+// readToken は入力から次のトークンを読む
+func (l *Lexer) readToken() (token.Token, error) {
+	for ch := l.readChar(); !l.eof; ch = l.readChar() {
+		start := l.Position
+		l.inToken = true
+		t, err := l.tokenize(ch)
+		l.inToken = false
+		if err != nil {
+			// 入力の上限を超えたか読むのに失敗した場合は、トークンのエラーよりそちらを返す
+			if l.err != nil {
+				return nil, l.err
 			}
-			tokens = append(tokens, token)
-		case ch == NullSymbol:
-			token, err := l.nullTokenize()
-			if err != nil {
-				return nil, err
+			end := l.ReadPosition
+			if l.eof {
+				end = l.Position
 			}
-			tokens = append(tokens, token)
-		case ch == WhiteSpaceSymbol, ch == WhiteSpaceTabSymbol, ch == WhiteSpaceCRSymbol, ch == WhiteSpaceLFSymbol:
+			l.span = token.Span{Start: start, End: end}
+			return nil, err
+		}
+		// 先読みに失敗した場合は、トークンが途中で切れているかもしれない
+		if l.err != nil {
+			return nil, l.err
+		}
+		if t == nil {
 			continue
-		case ch == QuoteSymbol:
-			token, err := l.stringTokenize()
-			if err != nil {
-				return nil, err
-			}
-			tokens = append(tokens, token)
-		case '0' <= ch && ch <= '9', ch == NumberPlusSymbol, ch == NumberMinusSymbol, ch == NumberDotSymbol:
-			// Numberは開始文字が[0-9]もしくは('+', '-', '.')
-			// e.g.
-			//     -1235
-			//     +10
-			//     .00001
-			token, err := l.numberTokenize()
-			if err != nil {
-				return nil, err
-			}
-			tokens = append(tokens, token)
-		default:
-			return nil, ErrLexer
 		}
+		l.span = token.Span{Start: start, End: l.ReadPosition}
+		return t, nil
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	return nil, io.EOF
+}
+
+// SkipRecord はWithRecordSeparatorの場合に、次のレコードの区切りの手前まで読み飛ばす
+// エラーになったレコードの残りを捨てて、次のレコードから読み直すのに使う
+// io.Readerから読むのに失敗した場合はそのエラーを返す
+func (l *Lexer) SkipRecord() error {
+	for !l.eof && l.peakChar() != RecordSeparatorSymbol {
+		l.readChar()
+	}
+	return l.err
+}
+
+// Spans はExecuteが返したトークンそれぞれの入力での位置を返す
+func (l *Lexer) Spans() []token.Span {
+	return l.spans
+}
+
+// Span はNextが最後に返したトークンの入力での位置を返す
+// Nextがトークンのエラーを返した場合は、エラーになったトークンの位置を返す
+// エラーになったトークンは読み飛ばしているので、その後もNextで続きを読める
+func (l *Lexer) Span() token.Span {
+	return l.span
+}
+
+// tokenize はchから始まるトークンを読む
+// 空白の場合はnilを返す
+func (l *Lexer) tokenize(ch rune) (token.Token, error) {
+	switch {
+	case ch == LeftBraceSymbol:
+		return token.LeftBraceToken{}, nil
+	case ch == RightBraceSymbol:
+		return token.RightBraceToken{}, nil
+	case ch == LeftBracketSymbol:
+		return token.LeftBracketToken{}, nil
+	case ch == RightBracketSymbol:
+		return token.RightBracketToken{}, nil
+	case ch == ColonSymbol:
+		return token.ColonToken{}, nil
+	case ch == CommaSymbol:
+		return token.CommaToken{}, nil
+	case ch == RecordSeparatorSymbol && l.recordSeparator:
+		return token.RecordSeparatorToken{}, nil
+	case ch == SlashSymbol && l.jsonc:
+		return l.commentTokenize()
+	case l.json5 && isIdentifierStart(ch):
+		return l.identifierTokenize()
+	case l.json5 && ch == SingleQuoteSymbol:
+		return l.stringTokenize(ch)
+	case l.json5 && isJSON5Space(ch):
+		return nil, nil
+	case ch == TrueSymbol:
+		return l.checkTruncated(l.boolTokenize(true))
+	case ch == FalseSymbol:
+		return l.checkTruncated(l.boolTokenize(false))
+	case ch == NullSymbol:
+		return l.checkTruncated(l.nullTokenize())
+	case ch == WhiteSpaceSymbol, ch == WhiteSpaceTabSymbol, ch == WhiteSpaceCRSymbol, ch == WhiteSpaceLFSymbol:
+		return nil, nil
+	case ch == QuoteSymbol:
+		return l.stringTokenize(ch)
+	case '0' <= ch && ch <= '9', ch == NumberPlusSymbol, ch == NumberMinusSymbol, ch == NumberDotSymbol:
+		// Numberは開始文字が[0-9]もしくは('+', '-', '.')
+		// e.g.
+		//     -1235
+		//     +10
+		//     .00001
+		if l.json5 {
+			return l.json5NumberTokenize()
+		}
+		return l.checkTruncated(l.numberTokenize())
+	default:
+		return nil, ErrLexer
 	}
-	return &tokens, nil
+}
+
+// checkTruncated はWithRecordSeparatorの場合に、トークンの直後がRSか入力の終わりならErrTruncatedにする
+// RFC 7464では数値などの後ろに空白がなければ途中で切れたものとして扱う
+func (l *Lexer) checkTruncated(t token.Token, err error) (token.Token, error) {
+	if err != nil || !l.recordSeparator {
+		return t, err
+	}
+	if ch := l.peakChar(); ch == 0 || ch == RecordSeparatorSymbol {
+		return nil, ErrTruncated
+	}
+	return t, nil
 }
 
 func (l *Lexer) readChar() rune {
-	// 入力が終わったらchを0に
-	if l.ReadPosition >= len(l.Input) {
+	if l.inToken && l.recordSeparator && l.peakChar() == RecordSeparatorSymbol {
+		// トークンの途中のRSは読まずに入力の終わりとして扱う
+		l.Ch = 0
+		return 0
+	}
+	if l.reader != nil {
+		l.Ch = l.readRune()
+	} else if l.ReadPosition >= len(l.Input) {
+		// 入力が終わったらchを0に
 		l.Ch = 0
+		l.eof = true
 	} else {
 		// まだ終わっていない場合readPositionをchにセット
 		l.Ch = l.Input[l.ReadPosition]
 	}
+	if !l.eof {
+		l.size += utf8.RuneLen(l.Ch)
+		if l.maxInputSize > 0 && l.size > l.maxInputSize {
+			// 上限を超えたら入力の終わりとして扱い、NextはErrInputTooLargeを返す
+			l.err = ErrInputTooLarge
+			l.eof = true
+			l.Ch = 0
+		}
+	}
 	// positionを次に進める
 	l.Position = l.ReadPosition
 	// readpositonを次に進める
@@ -134,6 +410,20 @@ func (l *Lexer) readChar() rune {
 }
 
 func (l *Lexer) peakChar() rune {
+	if l.reader != nil {
+		if len(l.peeked) == 0 {
+			r, _, err := l.reader.ReadRune()
+			if err != nil {
+				// 読むのに失敗した場合は、入力の終わりではないのでNextがそのエラーを返す
+				if err != io.EOF {
+					l.err = err
+				}
+				return 0
+			}
+			l.peeked = append(l.peeked, r)
+		}
+		return l.peeked[0]
+	}
 	// 入力が終わったらchを0に
 	if l.ReadPosition >= len(l.Input) {
 		return 0
@@ -142,102 +432,199 @@ func (l *Lexer) peakChar() rune {
 	}
 }
 
-func (l *Lexer) stringTokenize() (token.Token, error) {
+// readRune はio.Readerから1文字読む
+// 読み終えたかエラーになったら0を返す
+func (l *Lexer) readRune() rune {
+	if len(l.peeked) > 0 {
+		r := l.peeked[0]
+		l.peeked = l.peeked[1:]
+		return r
+	}
+	r, _, err := l.reader.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		l.eof = true
+		return 0
+	}
+	return r
+}
+
+// stringTokenize はquoteで囲まれた文字列を読む
+// quoteはJSON5の場合だけシングルクォートになる
+func (l *Lexer) stringTokenize(quote rune) (token.Token, error) {
 	str := []rune("")
-	utf16Buf := []rune{}
+	// high は直前の\uXXXXで読んだ上位サロゲート
+	high := rune(0)
 	for ch := l.readChar(); ch != 0; ch = l.readChar() {
-		switch ch {
-		case EscapeSymbol:
-			chNext := l.readChar()
-			switch chNext {
-			case QuoteSymbol:
-				str = append(str, chNext)
-				continue
-			case BackspaceSymbol, NewPageSymbol, TabSymbol, LFSymbol, CRSymbol, SlashSymbol:
-				str = append(str, EscapeSymbol)
-				str = append(str, chNext)
-				continue
-			case Utf16EscapeSymbol:
-				// UTF-16
-				// \u0000 ~ \uFFFF
-				// \uまで読み込んだので残りの0000~XXXXの4文字を読み込む
-				// UTF-16に関してはエスケープ処理を行う
-				hexString := ""
-				for i := 0; i < 4; i++ {
-					c := l.readChar()
-					if isAsciiHexdigit(c) {
-						hexString += string(c)
-					}
+		if high != 0 && (ch != EscapeSymbol || l.peakChar() != Utf16EscapeSymbol) {
+			// 対になる下位サロゲートがない
+			str = append(str, utf8.RuneError)
+			high = 0
+		}
+		if ch == quote {
+			return token.NewStringToken(string(str)), nil
+		}
+		if l.maxStringLength > 0 && len(str) >= l.maxStringLength {
+			return nil, l.skipString(quote, ErrStringTooLong)
+		}
+		if ch != EscapeSymbol {
+			str = append(str, ch)
+			continue
+		}
+		chNext := l.readChar()
+		switch chNext {
+		case QuoteSymbol, EscapeSymbol, SlashSymbol:
+			str = append(str, chNext)
+		case BackspaceSymbol:
+			str = append(str, '\b')
+		case NewPageSymbol:
+			str = append(str, '\f')
+		case LFSymbol:
+			str = append(str, '\n')
+		case CRSymbol:
+			str = append(str, '\r')
+		case TabSymbol:
+			str = append(str, '\t')
+		case Utf16EscapeSymbol:
+			// UTF-16
+			// \u0000 ~ \uFFFF
+			// \uまで読み込んだので残りの0000~XXXXの4文字を読み込む
+			hexString := ""
+			// 16進数でない文字は閉じるクォートかもしれないので読まない
+			for i := 0; i < 4; i++ {
+				if !isAsciiHexdigit(l.peakChar()) {
+					return nil, l.skipString(quote, ErrStringToHex)
 				}
-				hex, err := strconv.ParseInt(hexString, 16, 32)
-				if err != nil {
-					return nil, ErrStringToHex
+				hexString += string(l.readChar())
+			}
+			hex, err := strconv.ParseInt(hexString, 16, 32)
+			if err != nil {
+				return nil, l.skipString(quote, ErrStringToHex)
+			}
+			r := rune(hex)
+			switch {
+			case high != 0:
+				// サロゲートペアなら2つで1文字、そうでなければ上位サロゲートは不正な文字にする
+				if s := runeFromHexPairs([]rune{high, r}); s != utf8.RuneError {
+					str = append(str, s)
+					high = 0
+					continue
 				}
-
-				utf16Buf = append(utf16Buf, rune(hex))
-				// サロゲートペアが必要かどうか
-				if utf16.IsSurrogate(rune(hex)) {
-					// 既に2つ溜まっていたら1文字のruneに変換
-					if len(utf16Buf) == 2 {
-						if s := runeFromHexPairs(utf16Buf); s != 0 {
-							str = append(str, []rune(string(s))...)
-						}
-						utf16Buf = []rune{}
-					}
-					// 1つしか溜まっていない場合はもう一回探しにいく
-				} else {
-					// サロゲートペアが不要な場合は1文字のruneに変換
-					if s := runeFromOneHex(utf16Buf); s != 0 {
-						str = append(str, []rune(string(s))...)
-						utf16Buf = []rune{}
-					}
+				str = append(str, utf8.RuneError)
+				high = 0
+				if utf16.IsSurrogate(r) && r < 0xDC00 {
+					high = r
+					continue
 				}
-				continue
+				str = append(str, runeFromOneHex(r))
+			case utf16.IsSurrogate(r) && r < 0xDC00:
+				// 上位サロゲートは次の\uXXXXと合わせて1文字にする
+				high = r
+			default:
+				str = append(str, runeFromOneHex(r))
+			}
+		case 0:
+			return nil, ErrStringTokenize
+		default:
+			if !l.json5 {
+				return nil, l.skipString(quote, ErrStringTokenize)
+			}
+			r, add, ok := l.json5Escape(chNext)
+			if !ok {
+				return nil, l.skipString(quote, ErrStringTokenize)
+			}
+			if add {
+				str = append(str, r)
 			}
-		case QuoteSymbol:
-			return token.NewStringToken(string(str)), nil
 		}
-		str = append(str, ch)
 	}
 	return nil, ErrStringTokenize
 }
 
+// skipString はエラーになった文字列の残りを閉じるquoteまで読み飛ばしてerrを返す
+// 次のNextが文字列の途中から読まないようにする
+func (l *Lexer) skipString(quote rune, err error) error {
+	for ch := l.readChar(); ch != 0 && ch != quote; ch = l.readChar() {
+		if ch == EscapeSymbol {
+			l.readChar()
+		}
+	}
+	return err
+}
+
+// commentTokenize は//から行末まで、または/*から*/までのコメントを読む
+// WithCommentTokensでなければnilを返して空白と同じように読み飛ばす
+func (l *Lexer) commentTokenize() (token.Token, error) {
+	comment := []rune{l.Ch}
+	switch l.readChar() {
+	case SlashSymbol:
+		comment = append(comment, l.Ch)
+		// 改行は空白として読むので残す
+		for ch := l.peakChar(); ch != 0 && ch != WhiteSpaceLFSymbol && ch != WhiteSpaceCRSymbol; ch = l.peakChar() {
+			comment = append(comment, l.readChar())
+		}
+	case '*':
+		comment = append(comment, l.Ch)
+		for {
+			ch := l.readChar()
+			if ch == 0 {
+				return nil, ErrComment
+			}
+			comment = append(comment, ch)
+			if ch == '*' && l.peakChar() == SlashSymbol {
+				comment = append(comment, l.readChar())
+				break
+			}
+		}
+	default:
+		return nil, ErrComment
+	}
+	if !l.commentTokens {
+		return nil, nil
+	}
+	return token.NewCommentToken(string(comment)), nil
+}
+
 func (l *Lexer) boolTokenize(b bool) (token.Token, error) {
-	s := string(l.Ch)
 	if b {
-		for i := 0; i < 3; i++ {
-			s += string(l.readChar())
-		}
-		if s == "true" {
+		if l.readWord(4) == "true" {
 			return token.TrueToken{}, nil
 		}
 		return nil, ErrBoolTokenize
 	}
-	for i := 0; i < 4; i++ {
-		s += string(l.readChar())
-	}
-	if s == "false" {
+	if l.readWord(5) == "false" {
 		return token.FalseToken{}, nil
 	}
 	return nil, ErrBoolTokenize
 }
 
 func (l *Lexer) nullTokenize() (token.Token, error) {
-	s := string(l.Ch)
-	for i := 0; i < 3; i++ {
-		s += string(l.readChar())
-	}
-	if s == "null" {
+	if l.readWord(4) == "null" {
 		return token.NullToken{}, nil
 	}
 	return nil, ErrNullTokenize
 }
 
+// readWord はl.Chから英字をn文字まで読む
+// 英字でない文字は次のトークンのものなので読まない
+func (l *Lexer) readWord(n int) string {
+	s := string(l.Ch)
+	for i := 1; i < n && isAsciiLetter(l.peakChar()); i++ {
+		s += string(l.readChar())
+	}
+	return s
+}
+
 func (l *Lexer) numberTokenize() (token.Token, error) {
 	num := string(l.Ch)
 	for {
 		ch := l.peakChar()
 		if isNumberSymbol(ch) {
+			if l.maxStringLength > 0 && len(num) >= l.maxStringLength {
+				return nil, ErrStringTooLong
+			}
 			num += string(ch)
 			l.readChar()
 		} else {
@@ -256,6 +643,10 @@ func isNumberSymbol(s rune) bool {
 	return false
 }
 
+func isAsciiLetter(v rune) bool {
+	return ('a' <= v && v <= 'z') || ('A' <= v && v <= 'Z')
+}
+
 func isAsciiHexdigit(v rune) bool {
 	if ('0' <= v && v <= '9') || ('a' <= v && v <= 'f') || ('A' <= v && v <= 'F') {
 		return true
@@ -263,8 +654,13 @@ func isAsciiHexdigit(v rune) bool {
 	return false
 }
 
-func runeFromOneHex(rs []rune) rune {
-	return rs[0]
+// runeFromOneHex は\uXXXXの1つをruneにする
+// 対になっていない下位サロゲートは不正な文字にする
+func runeFromOneHex(r rune) rune {
+	if utf16.IsSurrogate(r) {
+		return utf8.RuneError
+	}
+	return r
 }
 
 func runeFromHexPairs(rs []rune) rune {
@@ -1,11 +1,16 @@
 package lexer
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf16"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sam8helloworld/json-go/token"
@@ -69,7 +74,7 @@ func TestSuccessStringTokenizeEscape(t *testing.T) {
 		token.CommaToken{},
 		token.NewStringToken("escape_slash"),
 		token.ColonToken{},
-		token.NewStringToken(`\/スラッシュ`),
+		token.NewStringToken("/スラッシュ"),
 		token.CommaToken{},
 		token.NewStringToken("escape_utf_16_text"),
 		token.ColonToken{},
@@ -81,7 +86,7 @@ func TestSuccessStringTokenizeEscape(t *testing.T) {
 		token.CommaToken{},
 		token.NewStringToken("escape_special_chars"),
 		token.ColonToken{},
-		token.NewStringToken(` \b \f \n \r \t \/ " `),
+		token.NewStringToken(" \b \f \n \r \t / \" "),
 		token.RightBraceToken{},
 	}
 	if diff := cmp.Diff(got, want, cmp.AllowUnexported(token.StringToken{})); diff != "" {
@@ -89,6 +94,60 @@ func TestSuccessStringTokenizeEscape(t *testing.T) {
 	}
 }
 
+func TestSuccessStringTokenizeEscapeSequence(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  string
+	}{
+		{name: "改行", input: `"x\ny"`, want: "x\ny"},
+		{name: "バックスラッシュ", input: `"\\n"`, want: `\n`},
+		{name: "NUL文字", input: `"a\u0000b"`, want: "a\x00b"},
+		{name: "対になっていない上位サロゲート", input: `"\uD83Da"`, want: "\uFFFDa"},
+		{name: "上位サロゲートが続く", input: `"\uD83D\uD83D\uDE04"`, want: "\uFFFD\U0001F604"},
+		{name: "対になっていない下位サロゲート", input: `"\uDE04"`, want: "\uFFFD"},
+		{name: "JSON5ではその他の文字はその文字自身", input: `"\a\'"`, opts: []Option{WithJSON5()}, want: "a'"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NewLexer(tt.input, tt.opts...).Execute()
+			if err != nil {
+				t.Fatalf("failed to execute lexer %#v", err)
+			}
+			want := &[]token.Token{token.NewStringToken(tt.want)}
+			if diff := cmp.Diff(got, want, cmp.AllowUnexported(token.StringToken{})); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedStringTokenizeEscapeSequence(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "不明なエスケープ", input: `"\x41"`, want: ErrStringTokenize},
+		{name: "シングルクォートのエスケープ", input: `"\'"`, want: ErrStringTokenize},
+		{name: "16進数でないユニコードエスケープ", input: `"\u12G4"`, want: ErrStringToHex},
+		{name: "JSON5では数字はエスケープできない", input: `"\1"`, opts: []Option{WithJSON5()}, want: ErrStringTokenize},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewLexer(tt.input, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
 func TestFailedStringTokenize(t *testing.T) {
 	f, err := os.Open("./testdata/string_only_fragile.json")
 	if err != nil {
@@ -308,3 +367,480 @@ func TestFailedLexer(t *testing.T) {
 		t.Fatalf("want ErrLexer, but got %v", err)
 	}
 }
+
+func TestSuccessSpans(t *testing.T) {
+	sut := NewLexer(`{"あ": [1.5, true] }`)
+	if _, err := sut.Execute(); err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	want := []token.Span{
+		{Start: 0, End: 1},
+		{Start: 1, End: 4},
+		{Start: 4, End: 5},
+		{Start: 6, End: 7},
+		{Start: 7, End: 10},
+		{Start: 10, End: 11},
+		{Start: 12, End: 16},
+		{Start: 16, End: 17},
+		{Start: 18, End: 19},
+	}
+	if diff := cmp.Diff(sut.Spans(), want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessNext(t *testing.T) {
+	input := `{"a": [1, "あ", null]}`
+	want, err := NewLexer(input).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	tests := []struct {
+		name string
+		sut  *Lexer
+	}{
+		{name: "文字列から読む", sut: NewLexer(input)},
+		{name: "io.Readerから読む", sut: NewReaderLexer(strings.NewReader(input))},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := []token.Token{}
+			for {
+				tok, err := tt.sut.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to tokenize %#v", err)
+				}
+				got = append(got, tok)
+			}
+			if diff := cmp.Diff(got, *want, cmp.AllowUnexported(token.StringToken{}, token.NumberToken{})); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+			if span := tt.sut.Span(); span != (token.Span{Start: 20, End: 21}) {
+				t.Errorf("unexpected span of the last token %v", span)
+			}
+		})
+	}
+}
+
+func TestSuccessNextAfterError(t *testing.T) {
+	type result struct {
+		token token.Token
+		err   error
+		span  token.Span
+	}
+	input := `[tru, "a\qb", "\u12", @, nul]`
+	want := []result{
+		{token: token.LeftBracketToken{}, span: token.Span{Start: 0, End: 1}},
+		{err: ErrBoolTokenize, span: token.Span{Start: 1, End: 4}},
+		{token: token.CommaToken{}, span: token.Span{Start: 4, End: 5}},
+		{err: ErrStringTokenize, span: token.Span{Start: 6, End: 12}},
+		{token: token.CommaToken{}, span: token.Span{Start: 12, End: 13}},
+		{err: ErrStringToHex, span: token.Span{Start: 14, End: 20}},
+		{token: token.CommaToken{}, span: token.Span{Start: 20, End: 21}},
+		{err: ErrLexer, span: token.Span{Start: 22, End: 23}},
+		{token: token.CommaToken{}, span: token.Span{Start: 23, End: 24}},
+		{err: ErrNullTokenize, span: token.Span{Start: 25, End: 28}},
+		{token: token.RightBracketToken{}, span: token.Span{Start: 28, End: 29}},
+	}
+	sut := NewLexer(input)
+	got := []result{}
+	for {
+		tok, err := sut.Next()
+		if err == io.EOF {
+			break
+		}
+		got = append(got, result{token: tok, err: err, span: sut.Span()})
+	}
+	if diff := cmp.Diff(got, want, cmp.AllowUnexported(result{}), cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedReaderLexer(t *testing.T) {
+	sut := NewReaderLexer(strings.NewReader(`[tru`))
+	if _, err := sut.Next(); err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	if _, err := sut.Next(); !errors.Is(err, ErrBoolTokenize) {
+		t.Fatalf("want ErrBoolTokenize, but got %v", err)
+	}
+}
+
+func TestFailedReaderLexerReadError(t *testing.T) {
+	errRead := errors.New("read error")
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{
+		{name: "数値の途中", input: `[1234`},
+		{name: "レコードの区切りの後ろ", input: "\x1e1234", opts: []Option{WithRecordSeparator()}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sut := NewReaderLexer(io.MultiReader(strings.NewReader(tt.input), iotest.ErrReader(errRead)), tt.opts...)
+			for {
+				tok, err := sut.Next()
+				if err == nil {
+					if _, ok := tok.(token.NumberToken); ok {
+						t.Fatalf("want %v, but got a truncated number %#v", errRead, tok)
+					}
+					continue
+				}
+				if !errors.Is(err, errRead) {
+					t.Fatalf("want %v, but got %v", errRead, err)
+				}
+				return
+			}
+		})
+	}
+}
+
+func TestSuccessRecordSeparator(t *testing.T) {
+	input := "\x1e[1, true]\n\x1e\"a\"\n"
+	want := []token.Token{
+		token.RecordSeparatorToken{},
+		token.LeftBracketToken{},
+		token.NewNumberToken("1"),
+		token.CommaToken{},
+		token.TrueToken{},
+		token.RightBracketToken{},
+		token.RecordSeparatorToken{},
+		token.NewStringToken("a"),
+	}
+	got, err := NewLexer(input, WithRecordSeparator()).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	if diff := cmp.Diff(*got, want, cmp.AllowUnexported(token.StringToken{}, token.NumberToken{})); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	// WithRecordSeparatorがなければRSは読めない
+	if _, err := NewLexer(input).Execute(); !errors.Is(err, ErrLexer) {
+		t.Fatalf("want ErrLexer, but got %v", err)
+	}
+}
+
+func TestFailedRecordSeparator(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "RSの直前で切れた数値", input: "123\x1e1\n", want: ErrTruncated},
+		{name: "入力の終わりで切れた数値", input: "123", want: ErrTruncated},
+		{name: "RSの直前で切れたtrue", input: "tr\x1e1\n", want: ErrBoolTokenize},
+		{name: "RSの直前で切れた文字列", input: "\"ab\x1e1\n", want: ErrStringTokenize},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sut := NewReaderLexer(strings.NewReader(tt.input), WithRecordSeparator())
+			if _, err := sut.Next(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			// 切れたレコードの後ろのRSは残っている
+			if err := sut.SkipRecord(); err != nil {
+				t.Fatalf("failed to skip %#v", err)
+			}
+			tok, err := sut.Next()
+			if tt.input == "123" {
+				if err != io.EOF {
+					t.Fatalf("want io.EOF, but got %v", err)
+				}
+				return
+			}
+			if _, ok := tok.(token.RecordSeparatorToken); !ok || err != nil {
+				t.Fatalf("want RecordSeparatorToken, but got %v (%v)", tok, err)
+			}
+		})
+	}
+}
+
+func TestSuccessJSONC(t *testing.T) {
+	input := "{\n  // コメント\n  \"a\": [1, /* 2, */ 3,],\n  \"b\": {\"c\": null,\n  },\n}"
+	tests := []struct {
+		name string
+		sut  *Lexer
+		want []token.Token
+	}{
+		{
+			name: "コメントを読み飛ばす",
+			sut:  NewLexer(input, WithJSONC()),
+			want: []token.Token{
+				token.LeftBraceToken{},
+				token.NewStringToken("a"),
+				token.ColonToken{},
+				token.LeftBracketToken{},
+				token.NewNumberToken("1"),
+				token.CommaToken{},
+				token.NewNumberToken("3"),
+				token.RightBracketToken{},
+				token.CommaToken{},
+				token.NewStringToken("b"),
+				token.ColonToken{},
+				token.LeftBraceToken{},
+				token.NewStringToken("c"),
+				token.ColonToken{},
+				token.NullToken{},
+				token.RightBraceToken{},
+				token.RightBraceToken{},
+			},
+		},
+		{
+			name: "コメントをトークンにする",
+			sut:  NewReaderLexer(strings.NewReader(input), WithCommentTokens()),
+			want: []token.Token{
+				token.LeftBraceToken{},
+				token.NewCommentToken("// コメント"),
+				token.NewStringToken("a"),
+				token.ColonToken{},
+				token.LeftBracketToken{},
+				token.NewNumberToken("1"),
+				token.CommaToken{},
+				token.NewCommentToken("/* 2, */"),
+				token.NewNumberToken("3"),
+				token.RightBracketToken{},
+				token.CommaToken{},
+				token.NewStringToken("b"),
+				token.ColonToken{},
+				token.LeftBraceToken{},
+				token.NewStringToken("c"),
+				token.ColonToken{},
+				token.NullToken{},
+				token.RightBraceToken{},
+				token.RightBraceToken{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.sut.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			if diff := cmp.Diff(*got, tt.want, cmp.AllowUnexported(token.StringToken{}, token.NumberToken{}, token.CommentToken{})); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedJSONC(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "WithJSONCがなければコメントは読めない", input: "[1 // a\n]", want: ErrLexer},
+		{name: "閉じていないコメント", input: "[1 /* a", opts: []Option{WithJSONC()}, want: ErrComment},
+		{name: "/が1つだけ", input: "[1 / a]", opts: []Option{WithJSONC()}, want: ErrComment},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewLexer(tt.input, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSuccessJSON5(t *testing.T) {
+	input := `{
+  // コメント
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here\'',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  escapes: '\x41\v\0',
+  null: [Infinity, -Infinity, NaN, -0x1F, true],
+}`
+	got, err := NewLexer(input, WithJSON5()).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	want := []token.Token{
+		token.LeftBraceToken{},
+		token.NewIdentifierToken("unquoted"), token.ColonToken{}, token.NewStringToken("and you can quote me on that"), token.CommaToken{},
+		token.NewIdentifierToken("singleQuotes"), token.ColonToken{}, token.NewStringToken(`I can use "double quotes" here'`), token.CommaToken{},
+		token.NewIdentifierToken("lineBreaks"), token.ColonToken{}, token.NewStringToken(`Look, Mom! No \n's!`), token.CommaToken{},
+		token.NewIdentifierToken("hexadecimal"), token.ColonToken{}, token.NewNumberToken("0xdecaf"), token.CommaToken{},
+		token.NewIdentifierToken("leadingDecimalPoint"), token.ColonToken{}, token.NewNumberToken(".8675309"), token.CommaToken{},
+		token.NewIdentifierToken("andTrailing"), token.ColonToken{}, token.NewNumberToken("8675309."), token.CommaToken{},
+		token.NewIdentifierToken("positiveSign"), token.ColonToken{}, token.NewNumberToken("+1"), token.CommaToken{},
+		token.NewIdentifierToken("escapes"), token.ColonToken{}, token.NewStringToken("A\v\x00"), token.CommaToken{},
+		token.NullToken{}, token.ColonToken{},
+		token.LeftBracketToken{},
+		token.NewIdentifierToken("Infinity"), token.CommaToken{},
+		token.NewNumberToken("-Infinity"), token.CommaToken{},
+		token.NewIdentifierToken("NaN"), token.CommaToken{},
+		token.NewNumberToken("-0x1F"), token.CommaToken{},
+		token.TrueToken{},
+		token.RightBracketToken{},
+		token.RightBraceToken{},
+	}
+	if diff := cmp.Diff(*got, want, cmp.AllowUnexported(token.StringToken{}, token.NumberToken{}, token.IdentifierToken{})); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	// WithJSON5がなければ識別子は読めない
+	if _, err := NewLexer(`{unquoted: 1}`).Execute(); !errors.Is(err, ErrLexer) {
+		t.Fatalf("want ErrLexer, but got %v", err)
+	}
+}
+
+func TestFailedLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "入力が大きすぎる", input: `["abc", "def"]`, opts: []Option{WithMaxInputSize(10)}, want: ErrInputTooLarge},
+		{name: "文字列の途中で入力が大きすぎる", input: `["abcdefghijk"]`, opts: []Option{WithMaxInputSize(10)}, want: ErrInputTooLarge},
+		{name: "文字列が長すぎる", input: `["abcd"]`, opts: []Option{WithMaxStringLength(3)}, want: ErrStringTooLong},
+		{name: "数値が長すぎる", input: `[1234]`, opts: []Option{WithMaxStringLength(3)}, want: ErrStringTooLong},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewLexer(tt.input, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			if _, err := NewReaderLexer(strings.NewReader(tt.input), tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v from io.Reader, but got %v", tt.want, err)
+			}
+		})
+	}
+	// 上限ちょうどは読める
+	if _, err := NewLexer(`["abc"]`, WithMaxInputSize(7), WithMaxStringLength(3)).Execute(); err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+}
+
+// encode はsをencodingのバイト列にする
+func encode(s string, encoding Encoding, bom bool) string {
+	if bom {
+		s = "\uFEFF" + s
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if encoding == UTF16LE || encoding == UTF32LE {
+		order = binary.LittleEndian
+	}
+	b := []byte{}
+	switch encoding {
+	case UTF16LE, UTF16BE:
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = append(b, 0, 0)
+			order.PutUint16(b[len(b)-2:], u)
+		}
+	case UTF32LE, UTF32BE:
+		for _, r := range s {
+			b = append(b, 0, 0, 0, 0)
+			order.PutUint32(b[len(b)-4:], uint32(r))
+		}
+	default:
+		b = []byte(s)
+	}
+	return string(b)
+}
+
+func TestSuccessEncoding(t *testing.T) {
+	input := "{\"a\": [\"あ\", \"\U0001F600\", 1]}"
+	want, err := NewLexer(input).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	tests := []struct {
+		name     string
+		encoding Encoding
+		bom      bool
+	}{
+		{name: "UTF-8", encoding: UTF8},
+		{name: "BOMのあるUTF-8", encoding: UTF8, bom: true},
+		{name: "UTF-16LE", encoding: UTF16LE},
+		{name: "BOMのあるUTF-16LE", encoding: UTF16LE, bom: true},
+		{name: "UTF-16BE", encoding: UTF16BE},
+		{name: "BOMのあるUTF-16BE", encoding: UTF16BE, bom: true},
+		{name: "UTF-32LE", encoding: UTF32LE},
+		{name: "BOMのあるUTF-32LE", encoding: UTF32LE, bom: true},
+		{name: "UTF-32BE", encoding: UTF32BE},
+		{name: "BOMのあるUTF-32BE", encoding: UTF32BE, bom: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			in := encode(input, tt.encoding, tt.bom)
+			for _, sut := range []*Lexer{NewLexer(in), NewReaderLexer(strings.NewReader(in))} {
+				got, err := sut.Execute()
+				if err != nil {
+					t.Fatalf("failed to tokenize %#v", err)
+				}
+				if diff := cmp.Diff(*got, *want, cmp.AllowUnexported(token.StringToken{}, token.NumberToken{})); diff != "" {
+					t.Fatalf("got differs: (-got +want)\n%s", diff)
+				}
+				if sut.Encoding() != tt.encoding || sut.BOM() != tt.bom {
+					t.Fatalf("want %v and BOM %v, but got %v and BOM %v", tt.encoding, tt.bom, sut.Encoding(), sut.BOM())
+				}
+			}
+		})
+	}
+	// 1文字だけのUTF-16も判定できる
+	got, err := NewLexer(encode("1", UTF16LE, false)).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	if diff := cmp.Diff(*got, []token.Token{token.NewNumberToken("1")}, cmp.AllowUnexported(token.NumberToken{})); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "UTF-16の途中で終わる", input: encode(`[1]`, UTF16LE, false) + "\x00", want: ErrEncoding},
+		{name: "UTF-32の途中で終わる", input: encode(`[1]`, UTF32BE, false)[:10], want: ErrEncoding},
+		{name: "先頭以外のBOM", input: "[\uFEFF1]", want: ErrLexer},
+		{name: "厳密なUTF-8ではBOMを受け付けない", input: encode(`[1]`, UTF8, true), opts: []Option{WithStrictUTF8()}, want: ErrEncoding},
+		{name: "厳密なUTF-8ではUTF-16を受け付けない", input: encode(`[1]`, UTF16BE, false), opts: []Option{WithStrictUTF8()}, want: ErrEncoding},
+		{name: "厳密なUTF-8ではUTF-32を受け付けない", input: encode(`[1]`, UTF32LE, true), opts: []Option{WithStrictUTF8()}, want: ErrEncoding},
+		{name: "厳密なUTF-8では不正なバイト列を受け付けない", input: "[\"\xff\"]", opts: []Option{WithStrictUTF8()}, want: ErrEncoding},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewLexer(tt.input, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			if _, err := NewReaderLexer(strings.NewReader(tt.input), tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v from io.Reader, but got %v", tt.want, err)
+			}
+		})
+	}
+	// 厳密なUTF-8でもBOMのないUTF-8は読める
+	if _, err := NewLexer(`["あ"]`, WithStrictUTF8()).Execute(); err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+}
@@ -2,15 +2,33 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
 	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/ndjson"
 	"github.com/sam8helloworld/json-go/parser"
 	"github.com/sam8helloworld/json-go/printer"
 )
 
+// commands はサブコマンド名と実行関数の対応
+// 戻り値は終了コード
+var commands = map[string]func(args []string) int{
+	"check":  runCheck,
+	"diff":   runDiff,
+	"fmt":    runFmt,
+	"gen":    runGen,
+	"schema": runSchema,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	fmt.Println("ファイル読み取り処理を開始します")
 	// ファイルをOpenする
 	f, err := os.Open("sample/sample.json")
@@ -37,3 +55,42 @@ func main() {
 	p := printer.NewPrinter(json)
 	p.Execute()
 }
+
+// load はファイルを読み込んでパースした結果を返す
+func load(path string) (interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lexer.NewLexer(string(b)).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	v, err := parser.NewParser(*tokens).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// loadLines は1行に1つの文書があるファイルを読み込んでパースする
+// 空行は無視する
+func loadLines(path string) ([]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := ndjson.NewReader(f)
+	docs := []interface{}{}
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		docs = append(docs, v)
+	}
+}
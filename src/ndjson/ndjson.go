@@ -0,0 +1,146 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/printer"
+)
+
+// LineError は何行目の文書が読めなかったかを表す
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPolicy は読めない行があったときの扱い
+type ErrorPolicy int
+
+const (
+	// Abort はエラーを返し、それ以降は読まない
+	Abort ErrorPolicy = iota
+	// Skip はその行を飛ばして次の行を読む。飛ばした行のエラーはErrorsで得られる
+	Skip
+)
+
+// Reader は1行に1つのJSONの文書があるNDJSON (JSON Lines) を1行ずつ読む
+// 空白だけの行は無視する
+type Reader struct {
+	reader *bufio.Reader
+	policy ErrorPolicy
+
+	line    int
+	skipped []*LineError
+	err     error
+}
+
+type Option func(*Reader)
+
+// WithErrorPolicy は読めない行があったときの扱いを指定する
+// 指定しない場合はAbort
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(r *Reader) {
+		r.policy = policy
+	}
+}
+
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	nr := &Reader{
+		reader: bufio.NewReader(r),
+	}
+	for _, opt := range opts {
+		opt(nr)
+	}
+	return nr
+}
+
+// Next は次の行の文書をパースして返す
+// 読み終えたらio.EOFを返す。パースできない行のエラーは*LineErrorになる
+func (r *Reader) Next() (interface{}, error) {
+	for r.err == nil {
+		b, err := r.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			r.err = err
+			break
+		}
+		if len(b) == 0 && err == io.EOF {
+			r.err = io.EOF
+			break
+		}
+		r.line++
+		if err == io.EOF {
+			// 最後の行の後ろに改行がなくてもよい
+			r.err = io.EOF
+		}
+		line := bytes.TrimSpace(b)
+		if len(line) == 0 {
+			continue
+		}
+		v, perr := parse(line)
+		if perr == nil {
+			// 最後の行の値を返してから次の呼び出しでio.EOFを返す
+			return v, nil
+		}
+		le := &LineError{Line: r.line, Err: perr}
+		if r.policy == Skip {
+			r.skipped = append(r.skipped, le)
+			continue
+		}
+		r.err = le
+		return nil, le
+	}
+	return nil, r.err
+}
+
+// Line は最後に読んだ行の行番号を返す
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Errors はSkipで飛ばした行のエラーを返す
+func (r *Reader) Errors() []*LineError {
+	return r.skipped
+}
+
+func parse(b []byte) (interface{}, error) {
+	tokens, err := lexer.NewLexer(string(b)).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewParser(*tokens).Execute()
+}
+
+// Writer は値を1行に1つずつ書き出す
+type Writer struct {
+	writer io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		writer: w,
+	}
+}
+
+// Write はvを改行を含まない1行にして書き出す
+// printer.Printerは文字列の中の改行もエスケープするので、1つの値が複数の行になることはない
+func (w *Writer) Write(v interface{}) error {
+	var buf bytes.Buffer
+	if err := printer.NewPrinter(v, printer.WithWriter(&buf)).Execute(); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := w.writer.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,84 @@
+package ndjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+const input = "{\"a\": 1}\r\n\n  [true, null]  \n{\"a\": \n\"x\"\n"
+
+func TestSuccessReader(t *testing.T) {
+	r := NewReader(strings.NewReader(input), WithErrorPolicy(Skip))
+	got := []interface{}{}
+	lines := []int{}
+	for {
+		v, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read %#v", err)
+		}
+		got = append(got, v)
+		lines = append(lines, r.Line())
+	}
+	want := []interface{}{
+		value.Object{"a": value.NumberInt(1)},
+		value.Array{value.Bool(true), value.Null},
+		value.String("x"),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if diff := cmp.Diff(lines, []int{1, 3, 5}); diff != "" {
+		t.Fatalf("line numbers differ: (-got +want)\n%s", diff)
+	}
+	if errs := r.Errors(); len(errs) != 1 || errs[0].Line != 4 || !errors.Is(errs[0], parser.ErrParse) {
+		t.Fatalf("want an error at line 4, but got %v", errs)
+	}
+}
+
+func TestFailedReader(t *testing.T) {
+	r := NewReader(strings.NewReader("1\n{\"a\": tru}\n2"))
+	if v, err := r.Next(); err != nil || v != value.NumberInt(1) {
+		t.Fatalf("want 1, but got %v (%v)", v, err)
+	}
+	_, err := r.Next()
+	var le *LineError
+	if !errors.As(err, &le) || le.Line != 2 || !errors.Is(err, lexer.ErrBoolTokenize) {
+		t.Fatalf("want an error at line 2, but got %v", err)
+	}
+	if want := "line 2: failed to bool tokenize"; err.Error() != want {
+		t.Errorf("want %s, but got %s", want, err.Error())
+	}
+	// Abortの場合はそれ以降を読まない
+	if _, err := r.Next(); !errors.As(err, &le) {
+		t.Fatalf("want the same error, but got %v", err)
+	}
+}
+
+func TestSuccessWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	values := []interface{}{
+		value.Object{"b": value.String("x\ny"), "a": value.Array{}},
+		value.NumberFloat(1.5),
+	}
+	for _, v := range values {
+		if err := w.Write(v); err != nil {
+			t.Fatalf("failed to write %#v", err)
+		}
+	}
+	want := "{\"a\":[],\"b\":\"x\\ny\"}\n1.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/sam8helloworld/json-go/ast"
+	"github.com/sam8helloworld/json-go/token"
+)
+
+var (
+	ErrNoSource = errors.New("source is required")
+)
+
+// ExecuteAST はExecuteと同じようにパースし、値の代わりに全てのノードの入力での位置を持つASTを返す
+// 位置を計算するのでWithSourceと一緒に使う。エラーはExecuteと同じものを返す
+func (p *Parser) ExecuteAST() (ast.Node, error) {
+	if p.spans == nil || len(p.spans) < len(p.Tokens) {
+		return nil, ErrNoSource
+	}
+	p.lines = lineStarts(p.source)
+	// value.Rawにはしない
+	p.isRaw = nil
+	n, err := p.parseNode()
+	if err != nil {
+		return nil, err
+	}
+	// 値の後ろに余分なトークンがあってはいけない
+	if p.index < len(p.Tokens) {
+		return nil, ErrParse
+	}
+	return n, nil
+}
+
+func (p *Parser) parseNode() (ast.Node, error) {
+	switch p.peek().(type) {
+	case token.LeftBraceToken:
+		return p.parseObjectNode()
+	case token.LeftBracketToken:
+		return p.parseArrayNode()
+	}
+	start := p.index
+	v, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Scalar{Range: p.rangeOf(start, start), Value: v}, nil
+}
+
+func (p *Parser) parseObjectNode() (*ast.Object, error) {
+	start := p.index
+	// { を読み飛ばす
+	p.next()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	object := &ast.Object{Members: []*ast.Member{}}
+	if _, ok := p.peek().(token.RightBraceToken); ok {
+		p.next()
+		object.Range = p.rangeOf(start, p.index-1)
+		return object, nil
+	}
+	for {
+		if err := p.checkMembers(len(object.Members) + 1); err != nil {
+			return nil, err
+		}
+		keyIndex := p.index
+		name, ok := p.key(p.next())
+		if _, colon := p.next().(token.ColonToken); !ok || !colon {
+			return nil, ErrInvalidKeyValuePair
+		}
+		v, err := p.parseNode()
+		if err != nil {
+			return nil, err
+		}
+		object.Members = append(object.Members, &ast.Member{
+			Range: p.rangeOf(keyIndex, p.index-1),
+			Key:   &ast.Key{Range: p.rangeOf(keyIndex, keyIndex), Name: name},
+			Value: v,
+		})
+
+		switch p.next().(type) {
+		case token.RightBraceToken:
+			object.Range = p.rangeOf(start, p.index-1)
+			return object, nil
+		case token.CommaToken:
+			continue
+		}
+		return nil, ErrParse
+	}
+}
+
+func (p *Parser) parseArrayNode() (*ast.Array, error) {
+	start := p.index
+	// [ を読み飛ばす
+	p.next()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	array := &ast.Array{Elements: []ast.Node{}}
+	if _, ok := p.peek().(token.RightBracketToken); ok {
+		p.next()
+		array.Range = p.rangeOf(start, p.index-1)
+		return array, nil
+	}
+	for {
+		if err := p.checkMembers(len(array.Elements) + 1); err != nil {
+			return nil, err
+		}
+		n, err := p.parseNode()
+		if err != nil {
+			if isLimit(err) {
+				return nil, err
+			}
+			return nil, ErrInvalidArrayValue
+		}
+		array.Elements = append(array.Elements, n)
+
+		switch p.next().(type) {
+		case token.RightBracketToken:
+			array.Range = p.rangeOf(start, p.index-1)
+			return array, nil
+		case token.CommaToken:
+			continue
+		}
+		return nil, ErrParse
+	}
+}
+
+// rangeOf はfirstからlastまでのトークンの入力での範囲を返す
+func (p *Parser) rangeOf(first, last int) ast.Range {
+	return ast.Range{
+		Start: p.position(p.spans[first].Start),
+		End:   p.position(p.spans[last].End),
+	}
+}
+
+// position はrune単位のインデックスを行と列にする
+func (p *Parser) position(offset int) ast.Position {
+	// offsetより後ろで始まる最初の行の1つ前の行
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > offset }) - 1
+	return ast.Position{
+		Offset: offset,
+		Line:   line + 1,
+		Column: offset - p.lines[line] + 1,
+	}
+}
+
+// lineStarts はそれぞれの行の先頭のインデックスを返す
+func lineStarts(source []rune) []int {
+	lines := []int{0}
+	for i, ch := range source {
+		if ch == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/token"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+// frame はExecuteIterativeがパース中のobjectか配列
+type frame struct {
+	object value.Object
+	array  value.Array
+	// key はobjectの場合に次の値のキー
+	key     string
+	members int
+	// raw はvalue.Rawにする値であること。startはその先頭のトークンの位置
+	raw   bool
+	start int
+}
+
+func (f *frame) value() interface{} {
+	if f.object != nil {
+		return f.object
+	}
+	return f.array
+}
+
+// ExecuteIterative はExecuteと同じ結果とエラーを返す
+// objectと配列を再帰呼び出しではなくスタックでパースするので、深くネストしてもゴルーチンのスタックを使わない
+func (p *Parser) ExecuteIterative() (interface{}, error) {
+	values, err := p.parseIterative()
+	if err != nil {
+		return nil, err
+	}
+	// 値の後ろに余分なトークンがあってはいけない
+	if p.index < len(p.Tokens) {
+		return nil, ErrParse
+	}
+	return values, nil
+}
+
+func (p *Parser) parseIterative() (interface{}, error) {
+	stack := []frame{}
+	// rawFrames はスタックの中のvalue.Rawにするobjectと配列の数
+	// その内側の値はRawにしない
+	rawFrames := 0
+	for {
+		// 値を1つ読む。objectか配列の場合は最初の値を読みに戻る
+		start := p.index
+		raw := rawFrames == 0 && p.isRaw != nil && p.spans != nil && p.isRaw(path(stack))
+		var v interface{}
+		switch p.peek().(type) {
+		case token.LeftBraceToken, token.LeftBracketToken:
+			_, isObject := p.next().(token.LeftBraceToken)
+			if err := p.enter(); err != nil {
+				return p.unwind(stack, len(stack), err)
+			}
+			f := frame{raw: raw, start: start}
+			if isObject {
+				f.object = value.Object{}
+			} else {
+				f.array = value.Array{}
+			}
+			stack = append(stack, f)
+			if raw {
+				rawFrames++
+			}
+			if !p.closeEmpty(&f) {
+				if err := p.beginMember(&stack[len(stack)-1]); err != nil {
+					return p.unwind(stack, len(stack)-1, err)
+				}
+				continue
+			}
+			v = f.value()
+			stack = stack[:len(stack)-1]
+			p.leave()
+			if raw {
+				rawFrames--
+			}
+		default:
+			var err error
+			if v, err = p.parseScalar(); err != nil {
+				return p.unwind(stack, len(stack), err)
+			}
+		}
+
+		// 値を読み終えたら親に追加して区切りを読む
+		// 閉じ括弧の場合は親も読み終えたので、さらにその親に追加する
+		for {
+			if raw {
+				if p.index > len(p.spans) {
+					return p.unwind(stack, len(stack), ErrParse)
+				}
+				v = value.Raw(p.source[p.spans[start].Start:p.spans[p.index-1].End])
+			}
+			if len(stack) == 0 {
+				return v, nil
+			}
+			f := &stack[len(stack)-1]
+			if f.object != nil {
+				f.object[f.key] = v
+			} else {
+				f.array = append(f.array, v)
+			}
+
+			t := p.next()
+			if _, ok := t.(token.CommaToken); ok {
+				if err := p.beginMember(f); err != nil {
+					return p.unwind(stack, len(stack)-1, err)
+				}
+				break
+			}
+			_, closeObject := t.(token.RightBraceToken)
+			_, closeArray := t.(token.RightBracketToken)
+			if (f.object != nil && !closeObject) || (f.object == nil && !closeArray) {
+				return p.unwind(stack, len(stack)-1, ErrParse)
+			}
+			v, raw, start = f.value(), f.raw, f.start
+			// 読み終えた値を残さない
+			*f = frame{}
+			stack = stack[:len(stack)-1]
+			p.leave()
+			if raw {
+				rawFrames--
+			}
+		}
+	}
+}
+
+// closeEmpty は空のobjectか配列の閉じ括弧を読む
+func (p *Parser) closeEmpty(f *frame) bool {
+	switch p.peek().(type) {
+	case token.RightBraceToken:
+		if f.object != nil {
+			p.next()
+			return true
+		}
+	case token.RightBracketToken:
+		if f.object == nil {
+			p.next()
+			return true
+		}
+	}
+	return false
+}
+
+// beginMember は次のメンバーか要素の数を検査し、objectの場合はキーとコロンを読む
+func (p *Parser) beginMember(f *frame) error {
+	if f.object == nil {
+		return p.checkMembers(len(f.array) + 1)
+	}
+	f.members++
+	if err := p.checkMembers(f.members); err != nil {
+		return err
+	}
+	key, ok := p.key(p.next())
+	if _, colon := p.next().(token.ColonToken); !ok || !colon {
+		return ErrInvalidKeyValuePair
+	}
+	f.key = key
+	return nil
+}
+
+// unwind はエラーを親に伝える
+// Executeと同じエラーにするため、下からn個のobjectと配列を子の値のエラーとして通し、配列はErrInvalidArrayValueにする
+func (p *Parser) unwind(stack []frame, n int, err error) (interface{}, error) {
+	for i := n - 1; i >= 0; i-- {
+		if stack[i].object == nil && !isLimit(err) {
+			err = ErrInvalidArrayValue
+		}
+	}
+	p.depth -= len(stack)
+	return nil, err
+}
+
+// path はスタックから次に読む値の位置を作る
+func path(stack []frame) pointer.Pointer {
+	path := make(pointer.Pointer, 0, len(stack))
+	for _, f := range stack {
+		if f.object != nil {
+			path = append(path, f.key)
+		} else {
+			path = path.AppendIndex(len(f.array))
+		}
+	}
+	return path
+}
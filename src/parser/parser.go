@@ -2,8 +2,11 @@ package parser
 
 import (
 	"errors"
+	"math"
 	"strconv"
+	"strings"
 
+	"github.com/sam8helloworld/json-go/pointer"
 	"github.com/sam8helloworld/json-go/token"
 	"github.com/sam8helloworld/json-go/value"
 )
@@ -16,36 +19,176 @@ var (
 	ErrInvalidNumberValue      = errors.New("invalid number value")
 	ErrInvalidBoolValue        = errors.New("invalid bool value")
 	ErrParse                   = errors.New("failed to parse")
+	ErrMaxDepth                = errors.New("exceeded max depth")
+	ErrTooManyMembers          = errors.New("too many members")
+)
+
+// DefaultMaxDepth はWithMaxDepthを指定しない場合のネストの深さの上限
+// 深くネストした入力でスタックを使い切らないようにする
+const DefaultMaxDepth = 10000
+
+// 信頼できない入力 (HTTPのリクエストなど) をパースする場合の上限の目安
+const (
+	SafeMaxDepth   = 1000
+	SafeMaxMembers = 1 << 16
 )
 
 type Parser struct {
 	Tokens []token.Token
 	index  int
+
+	source []rune
+	spans  []token.Span
+	isRaw  func(pointer.Pointer) bool
+	path   pointer.Pointer
+	json5  bool
+	// lines はExecuteASTで使う、それぞれの行の先頭のインデックス
+	lines []int
+	// diags はExecuteRecoverが見つけたエラー
+	diags Diagnostics
+
+	maxDepth   int
+	maxMembers int
+	depth      int
+}
+
+type Option func(*Parser)
+
+// WithSource はトークンの元になった入力と、lexer.Lexer.Spansが返すトークンの位置を渡す
+// value.Rawを作るのに必要になる
+func WithSource(input []rune, spans []token.Span) Option {
+	return func(p *Parser) {
+		p.source = input
+		p.spans = spans
+	}
+}
+
+// WithRawPaths は指定した位置の値をパースせずに入力のテキストのままvalue.Rawにする
+// WithSourceと一緒に使う
+func WithRawPaths(paths ...pointer.Pointer) Option {
+	return WithRawFunc(func(path pointer.Pointer) bool {
+		for _, raw := range paths {
+			if raw.String() == path.String() {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithRawFunc はfnがtrueを返した位置の値をパースせずに入力のテキストのままvalue.Rawにする
+// WithSourceと一緒に使う
+func WithRawFunc(fn func(path pointer.Pointer) bool) Option {
+	return func(p *Parser) {
+		p.isRaw = fn
+	}
+}
+
+// WithJSON5 はlexer.WithJSON5で読んだトークンをパースする
+// クォートしていないキーと、16進数、Infinity、NaNを受け付ける
+func WithJSON5() Option {
+	return func(p *Parser) {
+		p.json5 = true
+	}
+}
+
+// WithMaxDepth はobjectと配列のネストの深さの上限を指定する
+// 超えるとErrMaxDepthを返す。指定しない場合はDefaultMaxDepthで、0なら上限なし
+func WithMaxDepth(n int) Option {
+	return func(p *Parser) {
+		p.maxDepth = n
+	}
+}
+
+// WithMaxMembers は1つのobjectのメンバーと1つの配列の要素の数の上限を指定する
+// 超えるとErrTooManyMembersを返す。指定しないか0の場合は上限なし
+func WithMaxMembers(n int) Option {
+	return func(p *Parser) {
+		p.maxMembers = n
+	}
 }
 
-func NewParser(tokens []token.Token) *Parser {
-	return &Parser{
-		Tokens: tokens,
+// WithSafeLimits は信頼できない入力をパースするための上限をまとめて指定する
+// 深さはSafeMaxDepth、メンバーと要素の数はSafeMaxMembersまで
+// 入力と文字列の大きさはlexer.WithSafeLimitsで制限する
+func WithSafeLimits() Option {
+	return func(p *Parser) {
+		p.maxDepth = SafeMaxDepth
+		p.maxMembers = SafeMaxMembers
+	}
+}
+
+func NewParser(tokens []token.Token, opts ...Option) *Parser {
+	p := &Parser{
+		Tokens:   tokens,
+		maxDepth: DefaultMaxDepth,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.skipComments()
+	return p
+}
+
+// skipComments はlexer.WithCommentTokensで作ったトークンからコメントを取り除く
+// WithSourceの位置もトークンに合わせて取り除く
+func (p *Parser) skipComments() {
+	tokens := make([]token.Token, 0, len(p.Tokens))
+	spans := make([]token.Span, 0, len(p.spans))
+	for i, t := range p.Tokens {
+		if _, ok := t.(token.CommentToken); ok {
+			continue
+		}
+		tokens = append(tokens, t)
+		if i < len(p.spans) {
+			spans = append(spans, p.spans[i])
+		}
+	}
+	if len(tokens) == len(p.Tokens) {
+		return
+	}
+	p.Tokens = tokens
+	if p.spans != nil {
+		p.spans = spans
 	}
 }
 
 func (p *Parser) Execute() (interface{}, error) {
 	values, err := p.parse()
-	return values, err
+	if err != nil {
+		return nil, err
+	}
+	// 値の後ろに余分なトークンがあってはいけない
+	if p.index < len(p.Tokens) {
+		return nil, ErrParse
+	}
+	return values, nil
 }
 
 func (p *Parser) parse() (interface{}, error) {
-	t := p.peek()
-	switch t := t.(type) {
+	if p.isRaw != nil && p.spans != nil && p.isRaw(p.path) {
+		return p.parseRaw()
+	}
+	switch p.peek().(type) {
 	case token.LeftBraceToken:
 		return p.parseObject()
 	case token.LeftBracketToken:
 		return p.parseArray()
+	}
+	return p.parseScalar()
+}
+
+// parseScalar は文字列、数値、bool、nullをパースする
+func (p *Parser) parseScalar() (interface{}, error) {
+	switch t := p.peek().(type) {
 	case token.StringToken:
 		p.next()
 		return value.String(t.Value()), nil
 	case token.NumberToken:
 		p.next()
+		if p.json5 {
+			return parseJSON5Number(t.Value())
+		}
 		i, err := strconv.ParseInt(t.Value(), 10, 64)
 		if err == nil {
 			return value.NumberInt(i), nil
@@ -64,6 +207,13 @@ func (p *Parser) parse() (interface{}, error) {
 	case token.NullToken:
 		p.next()
 		return value.Null, nil
+	case token.IdentifierToken:
+		// 値にできる識別子はInfinityとNaNだけ
+		if v := t.Value(); p.json5 && (v == "Infinity" || v == "NaN") {
+			p.next()
+			return parseJSON5Number(v)
+		}
+		return nil, ErrParse
 	default:
 		return nil, ErrParse
 	}
@@ -79,27 +229,35 @@ func (p *Parser) parseObject() (value.Object, error) {
 	}
 	// { を読み飛ばす
 	p.next()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
 
 	object := value.Object{}
 
-	switch t.(type) {
+	switch p.peek().(type) {
 	case token.RightBraceToken:
+		p.next()
 		return object, nil
 	}
 
-	for {
+	for members := 1; ; members++ {
+		if err := p.checkMembers(members); err != nil {
+			return nil, err
+		}
 		t1 := p.next()
 		t2 := p.next()
 
-		t1t, t1Ok := t1.(token.StringToken)
+		key, t1Ok := p.key(t1)
 		_, t2Ok := t2.(token.ColonToken)
 
 		if t1Ok && t2Ok {
-			v, err := p.parse()
+			v, err := p.parseChild(key)
 			if err != nil {
 				return nil, err
 			}
-			object[t1t.Value()] = v
+			object[key] = v
 		} else {
 			return nil, ErrInvalidKeyValuePair
 		}
@@ -127,19 +285,30 @@ func (p *Parser) parseArray() (value.Array, error) {
 
 	// [ を読み飛ばす
 	p.next()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
 
 	array := value.Array{}
 	t = p.peek()
 	// ] なら空配列を返す
 	switch t.(type) {
 	case token.RightBracketToken:
+		p.next()
 		return array, nil
 	}
 
 	for {
+		if err := p.checkMembers(len(array) + 1); err != nil {
+			return nil, err
+		}
 		// 残りの`Value`をパースする
-		value, err := p.parse()
+		value, err := p.parseChild(strconv.Itoa(len(array)))
 		if err != nil {
+			if isLimit(err) {
+				return nil, err
+			}
 			return nil, ErrInvalidArrayValue
 		}
 		array = append(array, value)
@@ -157,11 +326,133 @@ func (p *Parser) parseArray() (value.Array, error) {
 	}
 }
 
+// enter は1段深いobjectか配列に入る
+// 深さの上限を超える場合は入らずにErrMaxDepthを返す
+func (p *Parser) enter() error {
+	if p.maxDepth > 0 && p.depth >= p.maxDepth {
+		return ErrMaxDepth
+	}
+	p.depth++
+	return nil
+}
+
+func (p *Parser) leave() {
+	p.depth--
+}
+
+// checkMembers はn個目のメンバーか要素が上限を超えていないか検査する
+func (p *Parser) checkMembers(n int) error {
+	if p.maxMembers > 0 && n > p.maxMembers {
+		return ErrTooManyMembers
+	}
+	return nil
+}
+
+// isLimit は上限を超えたエラーか判定する
+// 配列の要素のエラーでもErrInvalidArrayValueにせずにそのまま返す
+func isLimit(err error) bool {
+	return errors.Is(err, ErrMaxDepth) || errors.Is(err, ErrTooManyMembers)
+}
+
+// key はobjectのキーのトークンからキーを取り出す
+// JSON5の場合はクォートしていない識別子もキーにできる
+func (p *Parser) key(t token.Token) (string, bool) {
+	switch t := t.(type) {
+	case token.StringToken:
+		return t.Value(), true
+	case token.IdentifierToken:
+		return t.Value(), p.json5
+	case token.TrueToken:
+		return "true", p.json5
+	case token.FalseToken:
+		return "false", p.json5
+	case token.NullToken:
+		return "null", p.json5
+	}
+	return "", false
+}
+
+// parseJSON5Number はJSONの数値に加えて16進数とInfinity、NaNをパースする
+func parseJSON5Number(s string) (interface{}, error) {
+	sign, digits := "", s
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign, digits = s[:1], s[1:]
+	}
+	switch digits {
+	case "Infinity":
+		if sign == "-" {
+			return value.NumberFloat(math.Inf(-1)), nil
+		}
+		return value.NumberFloat(math.Inf(1)), nil
+	case "NaN":
+		return value.NumberFloat(math.NaN()), nil
+	}
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		u, err := strconv.ParseUint(digits[2:], 16, 64)
+		if err == nil && sign == "-" && u <= 1<<63 {
+			return value.NumberInt(-int64(u)), nil
+		}
+		if err == nil && u <= math.MaxInt64 {
+			return value.NumberInt(u), nil
+		}
+		// int64に収まらない場合は10進数と同じくfloatにする
+		f, err := strconv.ParseFloat(sign+digits+"p0", 64)
+		if err != nil {
+			return nil, ErrInvalidNumberValue
+		}
+		return value.NumberFloat(f), nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return value.NumberInt(i), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil {
+		return value.NumberFloat(f), nil
+	}
+	return nil, ErrInvalidNumberValue
+}
+
+// parseChild はobjectのメンバーや配列の要素をパースする
+// value.Rawにする位置を判定するため、パース中の値の位置を覚えておく
+func (p *Parser) parseChild(key string) (interface{}, error) {
+	if p.isRaw == nil {
+		return p.parse()
+	}
+	p.path = append(p.path, key)
+	defer func() {
+		p.path = p.path[:len(p.path)-1]
+	}()
+	return p.parse()
+}
+
+// parseRaw は値を検査だけして、入力のテキストをそのまま返す
+func (p *Parser) parseRaw() (interface{}, error) {
+	start := p.index
+	isRaw := p.isRaw
+	// 内側の値はRawにしない
+	p.isRaw = nil
+	_, err := p.parse()
+	p.isRaw = isRaw
+	if err != nil {
+		return nil, err
+	}
+	if p.index > len(p.spans) {
+		return nil, ErrParse
+	}
+	return value.Raw(p.source[p.spans[start].Start:p.spans[p.index-1].End]), nil
+}
+
+// peek はトークンを読み終えていたらnilを返す
 func (p *Parser) peek() token.Token {
+	if p.index >= len(p.Tokens) {
+		return nil
+	}
 	return p.Tokens[p.index]
 }
 
 func (p *Parser) next() token.Token {
+	t := p.peek()
 	p.index += 1
-	return p.Tokens[p.index-1]
+	return t
 }
@@ -1,9 +1,15 @@
 package parser
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/ast"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/pointer"
 	"github.com/sam8helloworld/json-go/token"
 	"github.com/sam8helloworld/json-go/value"
 )
@@ -135,3 +141,645 @@ func TestSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestSuccessEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []token.Token
+		want  interface{}
+	}{
+		{
+			name: "空のobject",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.RightBraceToken{},
+			},
+			want: value.Object{},
+		},
+		{
+			name: "空のobjectの後ろに要素が続くarray",
+			input: []token.Token{
+				token.LeftBracketToken{},
+				token.LeftBraceToken{},
+				token.RightBraceToken{},
+				token.CommaToken{},
+				token.LeftBracketToken{},
+				token.RightBracketToken{},
+				token.CommaToken{},
+				token.NullToken{},
+				token.RightBracketToken{},
+			},
+			want: value.Array{value.Object{}, value.Array{}, value.Null},
+		},
+		{
+			name: "空のarrayを含むobject",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.NewStringToken("key"),
+				token.ColonToken{},
+				token.LeftBracketToken{},
+				token.RightBracketToken{},
+				token.CommaToken{},
+				token.NewStringToken("empty"),
+				token.ColonToken{},
+				token.LeftBraceToken{},
+				token.RightBraceToken{},
+				token.RightBraceToken{},
+			},
+			want: value.Object{
+				"key":   value.Array{},
+				"empty": value.Object{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sut := NewParser(tt.input)
+			got, err := sut.Execute()
+			if err != nil {
+				t.Fatalf("failed to execute parser %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []token.Token
+		want  error
+	}{
+		{
+			name:  "トークンがない",
+			input: []token.Token{},
+			want:  ErrParse,
+		},
+		{
+			name: "objectが閉じていない",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.NewStringToken("key"),
+				token.ColonToken{},
+			},
+			want: ErrParse,
+		},
+		{
+			name: "arrayが閉じていない",
+			input: []token.Token{
+				token.LeftBracketToken{},
+				token.TrueToken{},
+				token.CommaToken{},
+			},
+			want: ErrInvalidArrayValue,
+		},
+		{
+			name: "値の後ろに余分なトークンがある",
+			input: []token.Token{
+				token.TrueToken{},
+				token.FalseToken{},
+			},
+			want: ErrParse,
+		},
+		{
+			name: "空のobjectの後ろに余分なトークンがある",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.RightBraceToken{},
+				token.RightBraceToken{},
+			},
+			want: ErrParse,
+		},
+		{
+			name: "[だけで終わる",
+			input: []token.Token{
+				token.LeftBracketToken{},
+			},
+			want: ErrInvalidArrayValue,
+		},
+		{
+			name: "キーの後ろで終わる",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.NewStringToken("key"),
+			},
+			want: ErrInvalidKeyValuePair,
+		},
+		{
+			name: "キーが文字列ではない",
+			input: []token.Token{
+				token.LeftBraceToken{},
+				token.TrueToken{},
+				token.ColonToken{},
+				token.TrueToken{},
+				token.RightBraceToken{},
+			},
+			want: ErrInvalidKeyValuePair,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sut := NewParser(tt.input)
+			got, err := sut.Execute()
+			if got != nil {
+				t.Errorf("want error %v, but got result %v", tt.want, got)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSuccessRaw(t *testing.T) {
+	input := `{"type": "event", "payload": {"b" : [1,  2], "a": "あ"}, "list": [{"x": 1}, { "x" : 2 }]}`
+	tests := []struct {
+		name string
+		opt  Option
+		want interface{}
+	}{
+		{
+			name: "JSON Pointerで指定",
+			opt:  WithRawPaths(pointer.Pointer{"payload"}, pointer.Pointer{"list", "1"}),
+			want: value.Object{
+				"type":    value.String("event"),
+				"payload": value.Raw(`{"b" : [1,  2], "a": "あ"}`),
+				"list": value.Array{
+					value.Object{"x": value.NumberInt(1)},
+					value.Raw(`{ "x" : 2 }`),
+				},
+			},
+		},
+		{
+			name: "関数で指定",
+			opt: WithRawFunc(func(path pointer.Pointer) bool {
+				return len(path) == 3 && path[0] == "list" && path[2] == "x"
+			}),
+			want: value.Object{
+				"type": value.String("event"),
+				"payload": value.Object{
+					"b": value.Array{value.NumberInt(1), value.NumberInt(2)},
+					"a": value.String("あ"),
+				},
+				"list": value.Array{
+					value.Object{"x": value.Raw("1")},
+					value.Object{"x": value.Raw("2")},
+				},
+			},
+		},
+		{
+			name: "ルートを指定",
+			opt:  WithRawPaths(pointer.Pointer{}),
+			want: value.Raw(input),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			l := lexer.NewLexer(input)
+			tokens, err := l.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			got, err := NewParser(*tokens, WithSource(l.Input, l.Spans()), tt.opt).Execute()
+			if err != nil {
+				t.Fatalf("failed to parse %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFailedRaw(t *testing.T) {
+	l := lexer.NewLexer(`{"payload": {"a" 1}}`)
+	tokens, err := l.Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	_, err = NewParser(*tokens, WithSource(l.Input, l.Spans()), WithRawPaths(pointer.Pointer{"payload"})).Execute()
+	if !errors.Is(err, ErrInvalidKeyValuePair) {
+		t.Fatalf("want ErrInvalidKeyValuePair, but got %v", err)
+	}
+}
+
+func TestSuccessComments(t *testing.T) {
+	input := `{
+	// 名前
+	"name": "a", /* 値 */ "list": [1, 2, /* 末尾 */],
+}`
+	l := lexer.NewLexer(input, lexer.WithCommentTokens())
+	tokens, err := l.Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	got, err := NewParser(*tokens, WithSource(l.Input, l.Spans()), WithRawPaths(pointer.Pointer{"list"})).Execute()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	want := value.Object{
+		"name": value.String("a"),
+		"list": value.Raw("[1, 2, /* 末尾 */]"),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessJSON5(t *testing.T) {
+	input := `{a: 0x1F, 'b': [Infinity, -Infinity, .5, +1,], null: "x", c: [0xFFFFFFFFFFFFFFFF, -0x8000000000000000, 0xFFFFFFFFFFFFFFFFFF]}`
+	tokens, err := lexer.NewLexer(input, lexer.WithJSON5()).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	got, err := NewParser(*tokens, WithJSON5()).Execute()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	want := value.Object{
+		"a":    value.NumberInt(31),
+		"b":    value.Array{value.NumberFloat(math.Inf(1)), value.NumberFloat(math.Inf(-1)), value.NumberFloat(0.5), value.NumberInt(1)},
+		"null": value.String("x"),
+		"c":    value.Array{value.NumberFloat(18446744073709551615), value.NumberInt(math.MinInt64), value.NumberFloat(4722366482869645213695)},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedJSON5(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "WithJSON5がなければ識別子のキーは使えない", input: `{a: 1}`, want: ErrInvalidKeyValuePair},
+		{name: "InfinityとNaN以外の識別子は値にできない", input: `{a: b}`, opts: []Option{WithJSON5()}, want: ErrParse},
+		{name: "数字のない16進数", input: `[0x]`, opts: []Option{WithJSON5()}, want: ErrInvalidArrayValue},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tokens, err := lexer.NewLexer(tt.input, lexer.WithJSON5()).Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			if _, err := NewParser(*tokens, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSuccessAST(t *testing.T) {
+	input := "{\n  \"a\": [1, \"あ\"],\n  \"b\": {}\n}"
+	l := lexer.NewLexer(input)
+	tokens, err := l.Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	got, err := NewParser(*tokens, WithSource(l.Input, l.Spans())).ExecuteAST()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	pos := func(offset, line, column int) ast.Position {
+		return ast.Position{Offset: offset, Line: line, Column: column}
+	}
+	want := &ast.Object{
+		Range: ast.Range{Start: pos(0, 1, 1), End: pos(30, 4, 2)},
+		Members: []*ast.Member{
+			{
+				Range: ast.Range{Start: pos(4, 2, 3), End: pos(17, 2, 16)},
+				Key:   &ast.Key{Range: ast.Range{Start: pos(4, 2, 3), End: pos(7, 2, 6)}, Name: "a"},
+				Value: &ast.Array{
+					Range: ast.Range{Start: pos(9, 2, 8), End: pos(17, 2, 16)},
+					Elements: []ast.Node{
+						&ast.Scalar{Range: ast.Range{Start: pos(10, 2, 9), End: pos(11, 2, 10)}, Value: value.NumberInt(1)},
+						&ast.Scalar{Range: ast.Range{Start: pos(13, 2, 12), End: pos(16, 2, 15)}, Value: value.String("あ")},
+					},
+				},
+			},
+			{
+				Range: ast.Range{Start: pos(21, 3, 3), End: pos(28, 3, 10)},
+				Key:   &ast.Key{Range: ast.Range{Start: pos(21, 3, 3), End: pos(24, 3, 6)}, Name: "b"},
+				Value: &ast.Object{Range: ast.Range{Start: pos(26, 3, 8), End: pos(28, 3, 10)}, Members: []*ast.Member{}},
+			},
+		},
+	}
+	if diff := cmp.Diff(got, ast.Node(want)); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	// 位置を除くとExecuteと同じ値になる
+	v, err := NewParser(*tokens).Execute()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	if diff := cmp.Diff(ast.ToValue(got), v); diff != "" {
+		t.Fatalf("value differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedAST(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "キーが文字列でない", input: `{1: 2}`, want: ErrInvalidKeyValuePair},
+		{name: "配列の要素が不正", input: `[1, }]`, want: ErrInvalidArrayValue},
+		{name: "値の後ろに余分なトークン", input: `{} 1`, want: ErrParse},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			l := lexer.NewLexer(tt.input)
+			tokens, err := l.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			if _, err := NewParser(*tokens, WithSource(l.Input, l.Spans())).ExecuteAST(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+	// WithSourceがないと位置がわからない
+	if _, err := NewParser([]token.Token{token.NullToken{}}).ExecuteAST(); !errors.Is(err, ErrNoSource) {
+		t.Fatalf("want ErrNoSource, but got %v", err)
+	}
+}
+
+func TestSuccessRecover(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      interface{}
+		wantDiags []string
+	}{
+		{
+			name:  "エラーがない",
+			input: `{"a": [1, 2]}`,
+			want:  value.Object{"a": value.Array{value.NumberInt(1), value.NumberInt(2)}},
+		},
+		{
+			name: "複数のエラー",
+			input: `{
+  "a": [1, , 3,],
+  "b" 2,
+  "c": {"d": :, "e": 5},
+  1: "x",
+  "f": "ok"
+}`,
+			want: value.Object{
+				"a": value.Array{value.NumberInt(1), value.NumberInt(3)},
+				"c": value.Object{"e": value.NumberInt(5)},
+				"f": value.String("ok"),
+			},
+			wantDiags: []string{
+				"2:12: failed to parse",
+				"2:16: failed to parse",
+				"3:7: invalid key value pair",
+				"4:14: failed to parse",
+				"5:3: invalid key value pair",
+			},
+		},
+		{
+			name:      "閉じていない配列",
+			input:     `[1, [2, 3`,
+			want:      value.Array{value.NumberInt(1), value.Array{value.NumberInt(2), value.NumberInt(3)}},
+			wantDiags: []string{"1:10: failed to parse"},
+		},
+		{
+			name:      "数値が不正で値の後ろに余分なトークンがある",
+			input:     `[1.2.3, 4] 5`,
+			want:      value.Array{value.NumberInt(4)},
+			wantDiags: []string{"1:2: invalid number value", "1:12: failed to parse"},
+		},
+		{
+			name:      "配列を}で閉じる",
+			input:     `[1, 2}`,
+			want:      value.Array{value.NumberInt(1), value.NumberInt(2)},
+			wantDiags: []string{"1:6: failed to parse"},
+		},
+		{
+			name:      "objectを]で閉じる",
+			input:     `{"a":1]`,
+			want:      value.Object{"a": value.NumberInt(1)},
+			wantDiags: []string{"1:7: failed to parse"},
+		},
+		{
+			name:      "配列の中のobjectを]で閉じる",
+			input:     `[{"a": 1], 2]`,
+			want:      value.Array{value.Object{"a": value.NumberInt(1)}, value.NumberInt(2)},
+			wantDiags: []string{"1:9: failed to parse"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			l := lexer.NewLexer(tt.input)
+			tokens, err := l.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			got, err := NewParser(*tokens, WithSource(l.Input, l.Spans())).ExecuteRecover()
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+			gotDiags := []string{}
+			var diags Diagnostics
+			if errors.As(err, &diags) {
+				for _, d := range diags {
+					gotDiags = append(gotDiags, d.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if tt.wantDiags == nil {
+				tt.wantDiags = []string{}
+			}
+			if diff := cmp.Diff(gotDiags, tt.wantDiags); diff != "" {
+				t.Fatalf("diagnostics differ: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewDiagnostic(t *testing.T) {
+	got := NewDiagnostic([]rune("[\n  1,\n  tru]"), 9, lexer.ErrBoolTokenize)
+	if want := "3:3: failed to bool tokenize"; got.Error() != want {
+		t.Fatalf("want %s, but got %s", want, got.Error())
+	}
+	if !errors.Is(got, lexer.ErrBoolTokenize) {
+		t.Fatalf("want lexer.ErrBoolTokenize, but got %v", got.Err)
+	}
+}
+
+func TestFailedLimits(t *testing.T) {
+	deep := strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000)
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{name: "デフォルトの深さの上限", input: deep, want: ErrMaxDepth},
+		{name: "objectの深さ", input: `{"a": {"b": {}}}`, opts: []Option{WithMaxDepth(2)}, want: ErrMaxDepth},
+		{name: "配列の中の深さ", input: `[1, [2, [3]]]`, opts: []Option{WithMaxDepth(2)}, want: ErrMaxDepth},
+		{name: "objectのメンバーの数", input: `{"a": 1, "b": 2, "c": 3}`, opts: []Option{WithMaxMembers(2)}, want: ErrTooManyMembers},
+		{name: "配列の要素の数", input: `[[1, 2, 3]]`, opts: []Option{WithMaxMembers(2)}, want: ErrTooManyMembers},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			l := lexer.NewLexer(tt.input)
+			tokens, err := l.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			if _, err := NewParser(*tokens, tt.opts...).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			opts := append([]Option{WithSource(l.Input, l.Spans())}, tt.opts...)
+			if _, err := NewParser(*tokens, opts...).ExecuteAST(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v from ExecuteAST, but got %v", tt.want, err)
+			}
+			if _, err := NewParser(*tokens, opts...).ExecuteRecover(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v from ExecuteRecover, but got %v", tt.want, err)
+			}
+		})
+	}
+	// 上限ちょうどはパースできる
+	tokens, err := lexer.NewLexer(`{"a": [1, 2]}`).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	if _, err := NewParser(*tokens, WithMaxDepth(2), WithMaxMembers(2)).Execute(); err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+}
+
+func TestIterative(t *testing.T) {
+	deep := strings.Repeat("[", 1000000) + strings.Repeat("]", 1000000)
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{
+		{name: "スカラー", input: `"a"`},
+		{name: "空のobjectと配列", input: `{"a": {}, "b": []}`},
+		{name: "ネストしたobjectと配列", input: `{"a": [1, {"b": [true, false, null]}, 2.5], "c": {"d": "e"}}`},
+		{name: "objectの中の誤った値", input: `{"a": {"b": [1, }]}}`},
+		{name: "配列の中の誤った値", input: `[[1, [2, }]]]`},
+		{name: "配列の中の誤ったキー", input: `[{"a": 1, 2: 3}]`},
+		{name: "配列の中の誤った区切り", input: `[[1 2]]`},
+		{name: "objectの中の誤った区切り", input: `{"a": {"b": 1 "c": 2}}`},
+		{name: "配列の閉じ括弧がobjectの閉じ括弧", input: `[1, 2}`},
+		{name: "空のobjectの閉じ括弧が配列の閉じ括弧", input: `[{]]`},
+		{name: "閉じていない", input: `{"a": [1, 2`},
+		{name: "値の後ろに余分なトークン", input: `[1] 2`},
+		{name: "誤った数値", input: `[1, 1e999999]`},
+		{name: "Rawにする位置", input: `{"a": {"b": [1, 2]}, "c": [{"d": 3}]}`, opts: []Option{WithRawPaths(pointer.Pointer{"a", "b"}, pointer.Pointer{"c"})}},
+		{name: "Rawにする値の中の誤り", input: `{"a": [1, }], "b": 2}`, opts: []Option{WithRawPaths(pointer.Pointer{"a"})}},
+		{name: "JSON5", input: `{a: [Infinity, 0x10,], 'b': -Infinity,}`, opts: []Option{WithJSON5()}},
+		{name: "デフォルトの深さの上限", input: deep},
+		{name: "配列の中の深さの上限", input: `[1, [2, [3]]]`, opts: []Option{WithMaxDepth(2)}},
+		{name: "配列の中のメンバーの数の上限", input: `[{"a": 1, "b": 2, "c": 3}]`, opts: []Option{WithMaxMembers(2)}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lopts := []lexer.Option{}
+			for _, opt := range tt.opts {
+				p := &Parser{}
+				opt(p)
+				if p.json5 {
+					lopts = append(lopts, lexer.WithJSON5())
+				}
+			}
+			l := lexer.NewLexer(tt.input, lopts...)
+			tokens, err := l.Execute()
+			if err != nil {
+				t.Fatalf("failed to tokenize %#v", err)
+			}
+			opts := append([]Option{WithSource(l.Input, l.Spans())}, tt.opts...)
+			want, wantErr := NewParser(*tokens, opts...).Execute()
+			got, err := NewParser(*tokens, opts...).ExecuteIterative()
+			if err != wantErr {
+				t.Fatalf("want %v, but got %v", wantErr, err)
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Fatalf("values differ: (-got +want)\n%s", diff)
+			}
+		})
+	}
+	// 再帰呼び出しではスタックが溢れる深さでもパースできる
+	tokens, err := lexer.NewLexer(deep).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	v, err := NewParser(*tokens, WithMaxDepth(0)).ExecuteIterative()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	for depth := 1; depth < 1000000; depth++ {
+		a, ok := v.(value.Array)
+		if !ok || len(a) != 1 {
+			t.Fatalf("want array at depth %d, but got %#v", depth, v)
+		}
+		v = a[0]
+	}
+	if diff := cmp.Diff(v, value.Array{}); diff != "" {
+		t.Fatalf("values differ: (-got +want)\n%s", diff)
+	}
+}
+
+func benchmarkInput(b *testing.B) []token.Token {
+	var s strings.Builder
+	s.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			s.WriteString(",")
+		}
+		s.WriteString(`{"id": 1, "name": "json", "tags": ["a", "b"], "nested": {"ok": true, "score": 1.5, "list": [[1], [2, [3]]]}}`)
+	}
+	s.WriteString("]")
+	tokens, err := lexer.NewLexer(s.String()).Execute()
+	if err != nil {
+		b.Fatalf("failed to tokenize %#v", err)
+	}
+	return *tokens
+}
+
+func BenchmarkExecute(b *testing.B) {
+	tokens := benchmarkInput(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser(tokens).Execute(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExecuteIterative(b *testing.B) {
+	tokens := benchmarkInput(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser(tokens).ExecuteIterative(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
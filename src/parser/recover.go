@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sam8helloworld/json-go/ast"
+	"github.com/sam8helloworld/json-go/token"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+// Diagnostic はExecuteRecoverが見つけた1つのエラーと、その入力での位置
+type Diagnostic struct {
+	Position ast.Position
+	Err      error
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %v", d.Position.Line, d.Position.Column, d.Err)
+}
+
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// NewDiagnostic はsourceのoffsetの位置のerrをDiagnosticにする
+// lexer.LexerのエラーをExecuteRecoverのエラーと一緒に報告するのに使う
+func NewDiagnostic(source []rune, offset int, err error) *Diagnostic {
+	p := &Parser{lines: lineStarts(source)}
+	return &Diagnostic{Position: p.position(offset), Err: err}
+}
+
+// Diagnostics はExecuteRecoverが見つけた全てのエラー
+type Diagnostics []*Diagnostic
+
+func (ds Diagnostics) Error() string {
+	msgs := make([]string, len(ds))
+	for i, d := range ds {
+		msgs[i] = d.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (ds Diagnostics) Is(target error) bool {
+	for _, d := range ds {
+		if errors.Is(d, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ds Diagnostics) As(target interface{}) bool {
+	for _, d := range ds {
+		if errors.As(d, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteRecover はエラーがあっても止まらずに最後までパースする
+// エラーになった値はカンマか閉じ括弧まで読み飛ばして次の値から読み直し、
+// 見つけた全てのエラーを入力の順にDiagnosticsで返す。値は読めた部分だけで組み立てる
+// 位置を計算するのでWithSourceと一緒に使う
+func (p *Parser) ExecuteRecover() (interface{}, error) {
+	if p.spans == nil || len(p.spans) < len(p.Tokens) {
+		return nil, ErrNoSource
+	}
+	p.lines = lineStarts(p.source)
+	p.isRaw = nil
+	p.diags = nil
+
+	v, _ := p.recoverValue()
+	// 値の後ろに余分なトークンがあってはいけない
+	if p.index < len(p.Tokens) {
+		p.report(ErrParse)
+		p.index = len(p.Tokens)
+	}
+	if len(p.diags) > 0 {
+		return v, p.diags
+	}
+	return v, nil
+}
+
+// recoverValue は値を1つパースする
+// 値が読めなかった場合は次のカンマか閉じ括弧の手前まで読み飛ばしてfalseを返す
+func (p *Parser) recoverValue() (interface{}, bool) {
+	switch p.peek().(type) {
+	case token.LeftBraceToken, token.LeftBracketToken:
+		if err := p.enter(); err != nil {
+			p.report(err)
+			p.skip()
+			return nil, false
+		}
+		defer p.leave()
+		if _, ok := p.peek().(token.LeftBraceToken); ok {
+			return p.recoverObject(), true
+		}
+		return p.recoverArray(), true
+	}
+	start := p.index
+	v, err := p.parse()
+	if err != nil {
+		p.index = start
+		p.report(err)
+		p.skip()
+		return nil, false
+	}
+	return v, true
+}
+
+func (p *Parser) recoverObject() value.Object {
+	// { を読み飛ばす
+	p.next()
+	object := value.Object{}
+	if _, ok := p.peek().(token.RightBraceToken); ok {
+		p.next()
+		return object
+	}
+	for members := 1; ; members++ {
+		if err := p.checkMembers(members); err != nil {
+			p.skipMembers(err, true)
+			return object
+		}
+		key, ok := p.key(p.peek())
+		if ok {
+			p.next()
+			_, ok = p.peek().(token.ColonToken)
+		}
+		if !ok {
+			p.report(ErrInvalidKeyValuePair)
+			p.skip()
+		} else {
+			p.next()
+			if v, ok := p.recoverValue(); ok {
+				object[key] = v
+			}
+		}
+		if !p.separator(true) {
+			return object
+		}
+	}
+}
+
+func (p *Parser) recoverArray() value.Array {
+	// [ を読み飛ばす
+	p.next()
+	array := value.Array{}
+	if _, ok := p.peek().(token.RightBracketToken); ok {
+		p.next()
+		return array
+	}
+	for elements := 1; ; elements++ {
+		if err := p.checkMembers(elements); err != nil {
+			p.skipMembers(err, false)
+			return array
+		}
+		if v, ok := p.recoverValue(); ok {
+			array = append(array, v)
+		}
+		if !p.separator(false) {
+			return array
+		}
+	}
+}
+
+// separator は値の後ろのカンマか閉じ括弧を読む
+// 次の値を読む場合はtrue、objectか配列が終わった場合はfalseを返す
+// objectはobjectの中の場合にtrueにする
+func (p *Parser) separator(object bool) bool {
+	switch p.peek().(type) {
+	case token.CommaToken:
+		p.next()
+		return true
+	case token.RightBraceToken, token.RightBracketToken:
+		_, brace := p.peek().(token.RightBraceToken)
+		if brace != object {
+			// 括弧の種類が違う場合は報告して、閉じたものとして扱う
+			p.report(ErrParse)
+		}
+		p.next()
+		return false
+	case nil:
+		p.report(ErrParse)
+		return false
+	}
+	p.report(ErrParse)
+	p.skip()
+	return p.separator(object)
+}
+
+// skipMembers はerrを記録して、objectか配列の残りのメンバーを閉じ括弧まで読み飛ばす
+func (p *Parser) skipMembers(err error, object bool) {
+	p.report(err)
+	p.skip()
+	for p.separator(object) {
+		p.skip()
+	}
+}
+
+// skip はエラーの後ろを同じ深さのカンマか閉じ括弧の手前まで読み飛ばす
+func (p *Parser) skip() {
+	depth := 0
+	for {
+		switch p.peek().(type) {
+		case nil:
+			return
+		case token.LeftBraceToken, token.LeftBracketToken:
+			depth++
+		case token.RightBraceToken, token.RightBracketToken:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case token.CommaToken:
+			if depth == 0 {
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// report は次のトークンの位置でエラーを記録する
+// 入力の終わりの場合は最後のトークンの末尾の位置にする
+// 同じ位置のエラーは最初の1つだけ記録する
+func (p *Parser) report(err error) {
+	offset := 0
+	if p.index < len(p.Tokens) {
+		offset = p.spans[p.index].Start
+	} else if len(p.Tokens) > 0 {
+		offset = p.spans[len(p.Tokens)-1].End
+	}
+	if n := len(p.diags); n > 0 && p.diags[n-1].Position.Offset == offset {
+		return
+	}
+	p.diags = append(p.diags, &Diagnostic{Position: p.position(offset), Err: err})
+}
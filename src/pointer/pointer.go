@@ -0,0 +1,104 @@
+package pointer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPointer = errors.New("invalid json pointer")
+)
+
+// Pointer はRFC 6901のJSON Pointerを参照トークンの列で表す
+// 空のPointerは文書全体を指す
+type Pointer []string
+
+func Parse(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, ErrInvalidPointer
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		u, err := unescape(t)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = u
+	}
+	return Pointer(tokens), nil
+}
+
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, t := range p {
+		b.WriteByte('/')
+		b.WriteString(escape(t))
+	}
+	return b.String()
+}
+
+// Append は末尾にトークンを追加した新しいPointerを返す
+// 元のPointerとは配列を共有しない
+func (p Pointer) Append(tokens ...string) Pointer {
+	np := make(Pointer, 0, len(p)+len(tokens))
+	np = append(np, p...)
+	return append(np, tokens...)
+}
+
+func (p Pointer) AppendIndex(i int) Pointer {
+	return p.Append(strconv.Itoa(i))
+}
+
+// Index はトークンを配列の添字として解釈する
+// 先頭の0や符号は許されない
+func Index(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for _, c := range token {
+		if c < '0' || '9' < c {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "~") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '~' {
+			b.WriteByte(s[i])
+			continue
+		}
+		// ~ の後ろは0か1のみ
+		if i+1 >= len(s) {
+			return "", ErrInvalidPointer
+		}
+		switch s[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", ErrInvalidPointer
+		}
+		i++
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,93 @@
+package pointer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSuccessParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Pointer
+	}{
+		{
+			name:  "文書全体",
+			input: "",
+			want:  Pointer{},
+		},
+		{
+			name:  "ネストしたキー",
+			input: "/a/b/0",
+			want:  Pointer{"a", "b", "0"},
+		},
+		{
+			name:  "空のキー",
+			input: "/",
+			want:  Pointer{""},
+		},
+		{
+			name:  "エスケープ",
+			input: "/a~1b/m~0n/~01",
+			want:  Pointer{"a/b", "m~n", "~1"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse pointer %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+			// 文字列に戻すと元に戻る
+			if s := got.String(); s != tt.input {
+				t.Errorf("want %s, but got %s", tt.input, s)
+			}
+		})
+	}
+}
+
+func TestFailedParse(t *testing.T) {
+	for _, input := range []string{"a", "/~", "/~2"} {
+		if _, err := Parse(input); !errors.Is(err, ErrInvalidPointer) {
+			t.Errorf("%q: want ErrInvalidPointer, but got %v", input, err)
+		}
+	}
+}
+
+func TestAppendDoesNotShare(t *testing.T) {
+	base := make(Pointer, 1, 4)
+	base[0] = "a"
+	p1 := base.Append("b")
+	p2 := base.Append("c")
+	if p1.String() != "/a/b" || p2.String() != "/a/c" {
+		t.Errorf("got %s and %s", p1, p2)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+		ok    bool
+	}{
+		{"0", 0, true},
+		{"12", 12, true},
+		{"01", 0, false},
+		{"-1", 0, false},
+		{"", 0, false},
+		{"a", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Index(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%q: want (%d, %t), but got (%d, %t)", tt.input, tt.want, tt.ok, got, ok)
+		}
+	}
+}
@@ -1,58 +1,248 @@
 package printer
 
 import (
-	"fmt"
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/sam8helloworld/json-go/value"
 )
 
+var (
+	ErrUnsupportedValue = errors.New("unsupported value")
+)
+
 type Printer struct {
-	value interface{}
+	value  interface{}
+	writer io.Writer
+	indent string
+	json5  bool
 }
 
-func NewPrinter(value interface{}) *Printer {
-	return &Printer{
+type Option func(*Printer)
+
+// WithWriter は出力先を指定する
+// 指定しない場合は標準出力に書き込む
+func WithWriter(w io.Writer) Option {
+	return func(p *Printer) {
+		p.writer = w
+	}
+}
+
+// WithIndent は配列とobjectの要素を1行ずつ、深さに応じてindentを繰り返して字下げして出力する
+// 指定しない場合は空白を入れずに1行で出力する
+func WithIndent(indent string) Option {
+	return func(p *Printer) {
+		p.indent = indent
+	}
+}
+
+// WithJSON5 はJSON5 (https://spec.json5.org/) で出力する
+// 識別子にできるキーはクォートせず、文字列はエスケープが少なくなる方のクォートで囲み、
+// 有限でない数値はInfinity、-Infinity、NaNにする
+func WithJSON5() Option {
+	return func(p *Printer) {
+		p.json5 = true
+	}
+}
+
+func NewPrinter(value interface{}, opts ...Option) *Printer {
+	p := &Printer{
 		value: value,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-func (p *Printer) Execute() {
-	print(p.value)
+func (p *Printer) Execute() error {
+	w := p.writer
+	if w == nil {
+		w = os.Stdout
+	}
+	bw := bufio.NewWriter(w)
+	if err := p.print(bw, p.value, 0); err != nil {
+		return err
+	}
+	return bw.Flush()
 }
 
-func print(val interface{}) {
+func (p *Printer) print(w *bufio.Writer, val interface{}, depth int) error {
 	switch v := val.(type) {
 	case value.NumberInt:
-		fmt.Printf("%d", v)
+		w.WriteString(strconv.FormatInt(int64(v), 10))
 	case value.NumberFloat:
-		fmt.Printf("%f", v)
+		if p.json5 && (math.IsNaN(float64(v)) || math.IsInf(float64(v), 0)) {
+			w.WriteString(formatNonFinite(float64(v)))
+			return nil
+		}
+		s, err := formatFloat(float64(v))
+		if err != nil {
+			return err
+		}
+		w.WriteString(s)
 	case value.Bool:
-		fmt.Printf("%t", v)
+		w.WriteString(strconv.FormatBool(bool(v)))
 	case value.String:
-		fmt.Printf("%s", v)
+		p.writeString(w, string(v))
+	case value.Raw:
+		// パースしていないテキストは空白も含めてそのまま書き出す
+		w.WriteString(string(v))
 	case value.Array:
-		fmt.Printf("[")
+		w.WriteByte('[')
 		for i, vi := range v {
-			print(vi)
+			p.newline(w, depth+1)
+			if err := p.print(w, vi, depth+1); err != nil {
+				return err
+			}
 			if i != len(v)-1 {
-				fmt.Printf(",")
+				w.WriteByte(',')
 			}
 		}
-		fmt.Printf("]")
+		if len(v) > 0 {
+			p.newline(w, depth)
+		}
+		w.WriteByte(']')
 	case value.Object:
-		fmt.Printf("{")
-		cnt := 0
-		for k, vi := range v {
-			fmt.Printf("\"%s\":", k)
-			print(vi)
-			if cnt != len(v)-1 {
-				fmt.Printf(",")
+		// mapの走査順はランダムなので、出力を安定させるためにキーをソートする
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.WriteByte('{')
+		for i, k := range keys {
+			p.newline(w, depth+1)
+			if p.json5 && isIdentifier(k) {
+				w.WriteString(k)
+			} else {
+				p.writeString(w, k)
+			}
+			w.WriteByte(':')
+			if p.indent != "" {
+				w.WriteByte(' ')
+			}
+			if err := p.print(w, v[k], depth+1); err != nil {
+				return err
 			}
-			cnt++
+			if i != len(keys)-1 {
+				w.WriteByte(',')
+			}
+		}
+		if len(keys) > 0 {
+			p.newline(w, depth)
 		}
-		fmt.Printf("}")
+		w.WriteByte('}')
 	default:
+		if val == value.Null {
+			w.WriteString("null")
+			return nil
+		}
+		return ErrUnsupportedValue
+	}
+	return nil
+}
+
+// newline は字下げする場合に改行してdepthの深さまで字下げする
+func (p *Printer) newline(w *bufio.Writer, depth int) {
+	if p.indent == "" {
+		return
+	}
+	w.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.WriteString(p.indent)
+	}
+}
+
+// formatFloat は桁を落とさずに数値を文字列にする
+// 極端に大きい・小さい値のみ指数表記にする
+func formatFloat(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrUnsupportedValue
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return strconv.FormatFloat(f, format, -1, 64), nil
+}
+
+// formatNonFinite はJSON5の有限でない数値を文字列にする
+func formatNonFinite(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case f > 0:
+		return "Infinity"
+	}
+	return "-Infinity"
+}
+
+// isIdentifier はsがJSON5でクォートせずに書けるキーか判定する
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) || r == '$' || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
 
+// writeString は文字列をクォートして書き出す
+// JSON5の場合は文字列に含まれる数が少ない方のクォートを使う
+func (p *Printer) writeString(w *bufio.Writer, s string) {
+	quote := byte('"')
+	if p.json5 && strings.Count(s, `"`) > strings.Count(s, "'") {
+		quote = '\''
 	}
+	writeString(w, s, quote)
+}
+
+const hex = "0123456789abcdef"
 
+func writeString(w *bufio.Writer, s string, quote byte) {
+	w.WriteByte(quote)
+	for _, r := range s {
+		switch r {
+		case rune(quote):
+			w.WriteByte('\\')
+			w.WriteByte(quote)
+		case '\\':
+			w.WriteString(`\\`)
+		case '\b':
+			w.WriteString(`\b`)
+		case '\f':
+			w.WriteString(`\f`)
+		case '\n':
+			w.WriteString(`\n`)
+		case '\r':
+			w.WriteString(`\r`)
+		case '\t':
+			w.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				// その他の制御文字は\u00XXの形でエスケープする
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[r>>4])
+				w.WriteByte(hex[r&0xF])
+				continue
+			}
+			w.WriteRune(r)
+		}
+	}
+	w.WriteByte(quote)
 }
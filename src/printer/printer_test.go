@@ -2,10 +2,14 @@ package printer
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"math"
 	"os"
 	"testing"
 
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
 	"github.com/sam8helloworld/json-go/value"
 )
 
@@ -34,3 +38,143 @@ func TestSuccess(t *testing.T) {
 		t.Errorf("want %s, but got %s", want, got)
 	}
 }
+
+func TestSuccessWithWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{
+			name:  "文字列はエスケープして出力",
+			input: value.String("a\"b\\c\nd\x01"),
+			want:  `"a\"b\\c\nd\u0001"`,
+		},
+		{
+			name:  "null",
+			input: value.Null,
+			want:  "null",
+		},
+		{
+			name: "小数",
+			input: value.Array{
+				value.NumberFloat(1.5),
+				value.NumberFloat(2e10),
+				value.NumberFloat(1e-10),
+			},
+			want: "[1.5,20000000000,1e-10]",
+		},
+		{
+			name: "objectのキーはソートして出力",
+			input: value.Object{
+				"c": value.Bool(true),
+				"a": value.Object{
+					"z": value.Null,
+					"y": value.String("y"),
+				},
+				"b": value.Array{},
+			},
+			want: `{"a":{"y":"y","z":null},"b":[],"c":true}`,
+		},
+		{
+			name: "Rawはそのまま出力",
+			input: value.Object{
+				"payload": value.Raw(`{ "b": 1,  "a": "\u3042" }`),
+			},
+			want: `{"payload":{ "b": 1,  "a": "\u3042" }}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			sut := NewPrinter(tt.input, WithWriter(&buf))
+			if err := sut.Execute(); err != nil {
+				t.Fatalf("failed to execute printer %#v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("want %s, but got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSuccessRoundTrip(t *testing.T) {
+	// lexerとparserで読んだ値を出力すると、エスケープを含めて元の入力に戻る
+	inputs := []string{
+		`{"a":"x\ny"}`,
+		`["\\","\\n","\"q\"","\b\f\r\t","\u0000\u001f"]`,
+		`{"\\key\n":"あ😄/"}`,
+	}
+	for _, in := range inputs {
+		tokens, err := lexer.NewLexer(in).Execute()
+		if err != nil {
+			t.Fatalf("failed to tokenize %s %#v", in, err)
+		}
+		v, err := parser.NewParser(*tokens).Execute()
+		if err != nil {
+			t.Fatalf("failed to parse %s %#v", in, err)
+		}
+		var buf bytes.Buffer
+		if err := NewPrinter(v, WithWriter(&buf)).Execute(); err != nil {
+			t.Fatalf("failed to execute printer %#v", err)
+		}
+		if got := buf.String(); got != in {
+			t.Errorf("want %s, but got %s", in, got)
+		}
+	}
+}
+
+func TestSuccessWithIndent(t *testing.T) {
+	input := value.Object{
+		"b": value.Array{value.NumberInt(1), value.Object{}},
+		"a": value.Array{},
+		"c": value.Object{"d": value.Null},
+	}
+	var buf bytes.Buffer
+	if err := NewPrinter(input, WithWriter(&buf), WithIndent("  ")).Execute(); err != nil {
+		t.Fatalf("failed to execute printer %#v", err)
+	}
+	want := `{
+  "a": [],
+  "b": [
+    1,
+    {}
+  ],
+  "c": {
+    "d": null
+  }
+}`
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, but got %s", want, got)
+	}
+}
+
+func TestFailedUnsupportedValue(t *testing.T) {
+	var buf bytes.Buffer
+	sut := NewPrinter(value.Array{value.NumberFloat(math.Inf(1))}, WithWriter(&buf))
+	if err := sut.Execute(); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatalf("want ErrUnsupportedValue, but got %v", err)
+	}
+}
+
+func TestSuccessWithJSON5(t *testing.T) {
+	input := value.Object{
+		"name":   value.String(`say "hi"`),
+		"it's":   value.String("it's"),
+		"$id_1":  value.Array{value.NumberFloat(math.Inf(1)), value.NumberFloat(math.Inf(-1)), value.NumberFloat(math.NaN())},
+		"1st":    value.Bool(true),
+		"キー":     value.Null,
+		"a-b":    value.NumberInt(1),
+		"normal": value.NumberFloat(1.5),
+	}
+	var buf bytes.Buffer
+	if err := NewPrinter(input, WithWriter(&buf), WithJSON5()).Execute(); err != nil {
+		t.Fatalf("failed to execute printer %#v", err)
+	}
+	want := `{$id_1:[Infinity,-Infinity,NaN],"1st":true,"a-b":1,"it's":"it's",name:'say "hi"',normal:1.5,キー:null}`
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, but got %s", want, got)
+	}
+}
@@ -0,0 +1,273 @@
+package sax
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/token"
+)
+
+var (
+	// SkipSubtree をStartObjectかStartArrayが返すと、閉じるまでの値のイベントを呼ばずに読み飛ばす
+	// Keyが返すとそのキーの値を読み飛ばす
+	SkipSubtree = errors.New("skip subtree")
+	// Stop をハンドラが返すと、そこで読むのをやめてExecuteはnilを返す
+	Stop = errors.New("stop parsing")
+)
+
+// Handler はParserが値を読むたびに呼ばれる
+// Stopと読み飛ばし以外のエラーを返すとExecuteはそのエラーを返す
+type Handler interface {
+	StartObject() error
+	Key(key string) error
+	EndObject() error
+	StartArray() error
+	EndArray() error
+	String(s string) error
+	// Number には入力の数値のテキストがそのまま渡される
+	Number(n string) error
+	Bool(b bool) error
+	Null() error
+}
+
+// NopHandler は何もしないHandler
+// 埋め込むと必要なメソッドだけを実装できる
+type NopHandler struct{}
+
+func (NopHandler) StartObject() error    { return nil }
+func (NopHandler) Key(key string) error  { return nil }
+func (NopHandler) EndObject() error      { return nil }
+func (NopHandler) StartArray() error     { return nil }
+func (NopHandler) EndArray() error       { return nil }
+func (NopHandler) String(s string) error { return nil }
+func (NopHandler) Number(n string) error { return nil }
+func (NopHandler) Bool(b bool) error     { return nil }
+func (NopHandler) Null() error           { return nil }
+
+// Parser はlexer.Lexerからトークンを1つずつ読み、値を組み立てずにHandlerを呼ぶ
+// エラーはparser.Parserと同じものを返す
+type Parser struct {
+	lexer   *lexer.Lexer
+	handler Handler
+}
+
+func NewParser(l *lexer.Lexer, h Handler) *Parser {
+	return &Parser{
+		lexer:   l,
+		handler: h,
+	}
+}
+
+func (p *Parser) Execute() error {
+	err := p.parse()
+	if err == Stop {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// 値の後ろに余分なトークンがあってはいけない
+	if _, err := p.lexer.Next(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return parser.ErrParse
+	}
+	return nil
+}
+
+func (p *Parser) parse() error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	return p.parseValue(t)
+}
+
+func (p *Parser) parseValue(t token.Token) error {
+	switch t := t.(type) {
+	case token.LeftBraceToken:
+		return p.parseObject()
+	case token.LeftBracketToken:
+		return p.parseArray()
+	case token.StringToken:
+		return ignoreSkip(p.handler.String(t.Value()))
+	case token.NumberToken:
+		n := t.Value()
+		// parser.Parserが数値にできないものはエラーにする
+		if _, err := strconv.ParseFloat(n, 64); err != nil {
+			return parser.ErrInvalidNumberValue
+		}
+		return ignoreSkip(p.handler.Number(n))
+	case token.TrueToken:
+		return ignoreSkip(p.handler.Bool(true))
+	case token.FalseToken:
+		return ignoreSkip(p.handler.Bool(false))
+	case token.NullToken:
+		return ignoreSkip(p.handler.Null())
+	}
+	return parser.ErrParse
+}
+
+func (p *Parser) parseObject() error {
+	if err := p.handler.StartObject(); err != nil {
+		if err == SkipSubtree {
+			return p.skip(true)
+		}
+		return err
+	}
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if _, ok := t.(token.RightBraceToken); ok {
+		return ignoreSkip(p.handler.EndObject())
+	}
+	for {
+		key, ok := t.(token.StringToken)
+		if !ok {
+			return parser.ErrInvalidKeyValuePair
+		}
+		t, err = p.next()
+		if err != nil {
+			return err
+		}
+		if _, ok := t.(token.ColonToken); !ok {
+			return parser.ErrInvalidKeyValuePair
+		}
+		switch err := p.handler.Key(key.Value()); err {
+		case nil:
+			if err := p.parse(); err != nil {
+				return err
+			}
+		case SkipSubtree:
+			if err := p.skipValue(); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		t, err = p.next()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.RightBraceToken:
+			return ignoreSkip(p.handler.EndObject())
+		case token.CommaToken:
+			if t, err = p.next(); err != nil {
+				return err
+			}
+			continue
+		}
+		return parser.ErrParse
+	}
+}
+
+func (p *Parser) parseArray() error {
+	if err := p.handler.StartArray(); err != nil {
+		if err == SkipSubtree {
+			return p.skip(false)
+		}
+		return err
+	}
+	t, err := p.next()
+	if err != nil {
+		return arrayValueError(err)
+	}
+	if _, ok := t.(token.RightBracketToken); ok {
+		return ignoreSkip(p.handler.EndArray())
+	}
+	for {
+		if err := p.parseValue(t); err != nil {
+			return arrayValueError(err)
+		}
+		t, err = p.next()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.RightBracketToken:
+			return ignoreSkip(p.handler.EndArray())
+		case token.CommaToken:
+			if t, err = p.next(); err != nil {
+				return arrayValueError(err)
+			}
+			continue
+		}
+		return parser.ErrParse
+	}
+}
+
+// arrayValueError はparser.Parserと同じく、配列の要素のパースのエラーをparser.ErrInvalidArrayValueにする
+// ハンドラとlexerのエラーはそのまま返す
+func arrayValueError(err error) error {
+	switch err {
+	case parser.ErrParse, parser.ErrInvalidKeyValuePair, parser.ErrInvalidNumberValue, parser.ErrInvalidBoolValue:
+		return parser.ErrInvalidArrayValue
+	}
+	return err
+}
+
+// skipValue は次の値をハンドラを呼ばずに読み飛ばす
+func (p *Parser) skipValue() error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	switch t.(type) {
+	case token.LeftBraceToken:
+		return p.skip(true)
+	case token.LeftBracketToken:
+		return p.skip(false)
+	case token.StringToken, token.NumberToken, token.TrueToken, token.FalseToken, token.NullToken:
+		return nil
+	}
+	return parser.ErrParse
+}
+
+// skip は開いた括弧の種類を積みながら、objectなら{、配列なら[の括弧が閉じるまで読み飛ばす
+// 開いた括弧と種類の違う閉じ括弧はparser.ErrParseにする
+func (p *Parser) skip(object bool) error {
+	stack := []bool{object}
+	for len(stack) > 0 {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.LeftBraceToken:
+			stack = append(stack, true)
+		case token.LeftBracketToken:
+			stack = append(stack, false)
+		case token.RightBraceToken, token.RightBracketToken:
+			_, closesObject := t.(token.RightBraceToken)
+			if stack[len(stack)-1] != closesObject {
+				return parser.ErrParse
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return nil
+}
+
+// next は次のトークンを返す
+// 値の途中で入力が終わった場合はparser.ErrParseにする
+func (p *Parser) next() (token.Token, error) {
+	t, err := p.lexer.Next()
+	if err == io.EOF {
+		return nil, parser.ErrParse
+	}
+	return t, err
+}
+
+func ignoreSkip(err error) error {
+	if err == SkipSubtree {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+package sax
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+)
+
+// recorder は呼ばれたイベントを記録する
+type recorder struct {
+	events []string
+	// skipKeys はSkipSubtreeを返すキー
+	skipKeys map[string]bool
+	// skipStart はSkipSubtreeを返す何番目かの開始イベント
+	skipStart int
+	starts    int
+	stopAt    string
+}
+
+func (r *recorder) record(event string) error {
+	r.events = append(r.events, event)
+	if event == r.stopAt {
+		return Stop
+	}
+	return nil
+}
+
+func (r *recorder) start(event string) error {
+	r.starts++
+	if err := r.record(event); err != nil {
+		return err
+	}
+	if r.starts == r.skipStart {
+		return SkipSubtree
+	}
+	return nil
+}
+
+func (r *recorder) StartObject() error { return r.start("{") }
+func (r *recorder) Key(key string) error {
+	if err := r.record("key " + key); err != nil {
+		return err
+	}
+	if r.skipKeys[key] {
+		return SkipSubtree
+	}
+	return nil
+}
+func (r *recorder) EndObject() error      { return r.record("}") }
+func (r *recorder) StartArray() error     { return r.start("[") }
+func (r *recorder) EndArray() error       { return r.record("]") }
+func (r *recorder) String(s string) error { return r.record(fmt.Sprintf("string %s", s)) }
+func (r *recorder) Number(n string) error { return r.record("number " + n) }
+func (r *recorder) Bool(b bool) error     { return r.record(fmt.Sprintf("bool %v", b)) }
+func (r *recorder) Null() error           { return r.record("null") }
+
+func TestSuccess(t *testing.T) {
+	input := `{"a": [1, 2.5e1, true], "b": {"c": null, "d": [{}]}, "e": "x"}`
+	tests := []struct {
+		name string
+		sut  *recorder
+		want []string
+	}{
+		{
+			name: "全てのイベント",
+			sut:  &recorder{},
+			want: []string{
+				"{", "key a", "[", "number 1", "number 2.5e1", "bool true", "]",
+				"key b", "{", "key c", "null", "key d", "[", "{", "}", "]", "}",
+				"key e", "string x", "}",
+			},
+		},
+		{
+			name: "キーの値を読み飛ばす",
+			sut:  &recorder{skipKeys: map[string]bool{"a": true, "b": true}},
+			want: []string{"{", "key a", "key b", "key e", "string x", "}"},
+		},
+		{
+			name: "objectを読み飛ばす",
+			sut:  &recorder{skipStart: 3},
+			want: []string{"{", "key a", "[", "number 1", "number 2.5e1", "bool true", "]", "key b", "{", "key e", "string x", "}"},
+		},
+		{
+			name: "途中でやめる",
+			sut:  &recorder{stopAt: "key b"},
+			want: []string{"{", "key a", "[", "number 1", "number 2.5e1", "bool true", "]", "key b"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := NewParser(lexer.NewReaderLexer(strings.NewReader(input)), tt.sut).Execute(); err != nil {
+				t.Fatalf("failed to parse %#v", err)
+			}
+			if diff := cmp.Diff(tt.sut.events, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+// counter はNopHandlerを埋め込んで数値だけを数える
+type counter struct {
+	NopHandler
+	numbers int
+}
+
+func (c *counter) Number(n string) error {
+	c.numbers++
+	return nil
+}
+
+func TestSuccessNopHandler(t *testing.T) {
+	c := &counter{}
+	if err := NewParser(lexer.NewLexer(`[1, {"a": 2}, "3"]`), c).Execute(); err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	if c.numbers != 2 {
+		t.Errorf("want 2 numbers, but got %d", c.numbers)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	errHandler := errors.New("handler error")
+	tests := []struct {
+		name    string
+		input   string
+		handler Handler
+		want    error
+	}{
+		{name: "入力がない", input: ``, handler: NopHandler{}, want: parser.ErrParse},
+		{name: "objectが閉じていない", input: `{"a": 1`, handler: NopHandler{}, want: parser.ErrParse},
+		{name: "読み飛ばし中に入力が終わる", input: `{"a": [1, [2]`, handler: &recorder{skipKeys: map[string]bool{"a": true}}, want: parser.ErrParse},
+		{name: "読み飛ばし中の閉じ括弧の種類が違う", input: `{"a":[1}}`, handler: &recorder{skipKeys: map[string]bool{"a": true}}, want: parser.ErrParse},
+		{name: "読み飛ばした配列の閉じ括弧の種類が違う", input: `{"a":[1}}`, handler: &recorder{skipStart: 2}, want: parser.ErrParse},
+		{name: "閉じ括弧の種類が違う", input: `{"a":[1}}`, handler: NopHandler{}, want: parser.ErrParse},
+		{name: "キーが文字列ではない", input: `{1: 1}`, handler: NopHandler{}, want: parser.ErrInvalidKeyValuePair},
+		{name: "余分なトークン", input: `1 2`, handler: NopHandler{}, want: parser.ErrParse},
+		{name: "字句解析のエラー", input: `[nul]`, handler: NopHandler{}, want: lexer.ErrNullTokenize},
+		{name: "ハンドラのエラー", input: `[1]`, handler: errorHandler{err: errHandler}, want: errHandler},
+		{name: "配列の要素のエラー", input: `[{1: 1}]`, handler: NopHandler{}, want: parser.ErrInvalidArrayValue},
+		{name: "ネストした配列の要素のエラー", input: `{"a": [[1, }]]}`, handler: NopHandler{}, want: parser.ErrInvalidArrayValue},
+		{name: "配列の要素の数値のエラー", input: `[1e999999]`, handler: NopHandler{}, want: parser.ErrInvalidArrayValue},
+		{name: "配列の要素がない", input: `[`, handler: NopHandler{}, want: parser.ErrInvalidArrayValue},
+		{name: "カンマの後ろで配列が終わる", input: `[1,`, handler: NopHandler{}, want: parser.ErrInvalidArrayValue},
+		{name: "配列の区切りのエラー", input: `[1 2]`, handler: NopHandler{}, want: parser.ErrParse},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := NewParser(lexer.NewLexer(tt.input), tt.handler).Execute()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			if _, ok := tt.handler.(NopHandler); !ok {
+				return
+			}
+			// parser.Parserと同じエラーを返す
+			tokens, err := lexer.NewLexer(tt.input).Execute()
+			if err != nil {
+				return
+			}
+			if _, err := parser.NewParser(*tokens).Execute(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v from parser.Parser, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+type errorHandler struct {
+	NopHandler
+	err error
+}
+
+func (h errorHandler) Number(n string) error {
+	return h.err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/schema"
+)
+
+// runSchema はJSON Schemaを扱う
+// 成功すれば0、エラーの場合は2を返す
+//
+//	json-go schema infer [-enum N] [-lines] file...
+func runSchema(args []string) int {
+	if len(args) == 0 || args[0] != "infer" {
+		fmt.Fprintln(os.Stderr, "usage: json-go schema infer [flags] file...")
+		return 2
+	}
+	fs := flag.NewFlagSet("schema infer", flag.ContinueOnError)
+	enum := fs.Int("enum", 5, "文字列をenumにする値の種類の上限 (0ならenumにしない)")
+	lines := fs.Bool("lines", false, "全てのファイルを1行に1つの文書があるNDJSONとして読む")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: json-go schema infer [flags] file...")
+		return 2
+	}
+
+	samples := []interface{}{}
+	for _, path := range fs.Args() {
+		// 拡張子が.ndjsonと.jsonlのファイルはNDJSONとして読む
+		if ext := filepath.Ext(path); *lines || ext == ".ndjson" || ext == ".jsonl" {
+			docs, err := loadLines(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			samples = append(samples, docs...)
+			continue
+		}
+		v, err := load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		samples = append(samples, v)
+	}
+
+	doc, err := schema.NewInferrer(samples, schema.WithEnumLimit(*enum)).Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := printer.NewPrinter(doc, printer.WithWriter(w), printer.WithIndent("  ")).Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	w.WriteByte('\n')
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrNoSample = errors.New("no sample documents")
+)
+
+const draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// Inferrer はサンプルの文書からスキーマを推測する
+// 型、全てのサンプルにあるキーのrequired、値の種類が少ない文字列のenum、数値の範囲を推測する
+// enumは値が全て文字列かnullの位置だけに付ける
+type Inferrer struct {
+	samples   []interface{}
+	enumLimit int
+}
+
+type InferOption func(*Inferrer)
+
+// WithEnumLimit は文字列をenumにする値の種類の上限を指定する
+// 指定しない場合は5で、0ならenumにしない
+func WithEnumLimit(n int) InferOption {
+	return func(i *Inferrer) {
+		i.enumLimit = n
+	}
+}
+
+func NewInferrer(samples []interface{}, opts ...InferOption) *Inferrer {
+	i := &Inferrer{
+		samples:   samples,
+		enumLimit: 5,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Execute は推測したスキーマの文書を返す
+func (i *Inferrer) Execute() (value.Object, error) {
+	if len(i.samples) == 0 {
+		return nil, ErrNoSample
+	}
+	root := &stats{}
+	for _, s := range i.samples {
+		i.add(root, s)
+	}
+	doc := i.schema(root)
+	doc["$schema"] = value.String(draft202012)
+	return doc, nil
+}
+
+// stats はサンプル中の同じ位置に現れた値を集計したもの
+type stats struct {
+	nulls, bools, ints, floats, strings, arrays, objects int
+
+	min, max interface{}
+	// values は文字列の値ごとの出現回数。種類が上限を超えたらnilにする
+	values   map[string]int
+	tooMany  bool
+	items    *stats
+	props    map[string]*stats
+	presence map[string]int
+}
+
+func (i *Inferrer) add(s *stats, v interface{}) {
+	switch val := v.(type) {
+	case value.Bool:
+		s.bools++
+	case value.NumberInt, value.NumberFloat:
+		if _, ok := val.(value.NumberInt); ok {
+			s.ints++
+		} else {
+			s.floats++
+		}
+		if s.min == nil || value.Compare(val, s.min) < 0 {
+			s.min = val
+		}
+		if s.max == nil || value.Compare(val, s.max) > 0 {
+			s.max = val
+		}
+	case value.String:
+		s.strings++
+		if s.tooMany {
+			break
+		}
+		if s.values == nil {
+			s.values = map[string]int{}
+		}
+		s.values[string(val)]++
+		if len(s.values) > i.enumLimit {
+			s.values, s.tooMany = nil, true
+		}
+	case value.Array:
+		s.arrays++
+		for _, vi := range val {
+			if s.items == nil {
+				s.items = &stats{}
+			}
+			i.add(s.items, vi)
+		}
+	case value.Object:
+		s.objects++
+		if s.props == nil {
+			s.props = map[string]*stats{}
+			s.presence = map[string]int{}
+		}
+		for k, vi := range val {
+			p, ok := s.props[k]
+			if !ok {
+				p = &stats{}
+				s.props[k] = p
+			}
+			s.presence[k]++
+			i.add(p, vi)
+		}
+	default:
+		s.nulls++
+	}
+}
+
+func (i *Inferrer) schema(s *stats) value.Object {
+	schema := value.Object{}
+	types := value.Array{}
+	if s.objects > 0 {
+		types = append(types, value.String("object"))
+		props := value.Object{}
+		required := value.Array{}
+		for k, p := range s.props {
+			props[k] = i.schema(p)
+		}
+		for _, k := range value.SortedKeys(props) {
+			if s.presence[k] == s.objects {
+				required = append(required, value.String(k))
+			}
+		}
+		schema["properties"] = props
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	if s.arrays > 0 {
+		types = append(types, value.String("array"))
+		if s.items != nil {
+			schema["items"] = i.schema(s.items)
+		}
+	}
+	if s.strings > 0 {
+		types = append(types, value.String("string"))
+		// 値が繰り返し現れる場合だけ種類が少ないとみなす
+		// 文字列とnull以外の値もあるとenumがそれらを受け付けないので、enumにしない
+		others := s.bools + s.ints + s.floats + s.arrays + s.objects
+		if !s.tooMany && s.strings > len(s.values) && others == 0 {
+			schema["enum"] = i.enum(s)
+		}
+	}
+	if s.ints+s.floats > 0 {
+		if s.floats > 0 {
+			types = append(types, value.String("number"))
+		} else {
+			types = append(types, value.String("integer"))
+		}
+		schema["minimum"] = s.min
+		schema["maximum"] = s.max
+	}
+	if s.bools > 0 {
+		types = append(types, value.String("boolean"))
+	}
+	if s.nulls > 0 {
+		types = append(types, value.String("null"))
+		if enum, ok := schema["enum"].(value.Array); ok {
+			schema["enum"] = append(enum, value.Null)
+		}
+	}
+	if len(types) == 1 {
+		schema["type"] = types[0]
+	} else {
+		schema["type"] = types
+	}
+	return schema
+}
+
+func (i *Inferrer) enum(s *stats) value.Array {
+	values := make([]string, 0, len(s.values))
+	for v := range s.values {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	enum := make(value.Array, len(values))
+	for j, v := range values {
+		enum[j] = value.String(v)
+	}
+	return enum
+}
@@ -0,0 +1,511 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrInvalidSchema  = errors.New("invalid schema")
+	ErrUnresolvedRef  = errors.New("unresolved $ref")
+	ErrUnsupportedRef = errors.New("unsupported $ref")
+	// ErrValidation はValidateが返すValidationErrorのどれにも一致する
+	ErrValidation = errors.New("validation failed")
+)
+
+// Schema はコンパイル済みのJSON Schema (draft 2020-12)
+// unevaluatedItemsやunevaluatedPropertiesなど、ここにないキーワードは無視する
+type Schema struct {
+	// always はtrueやfalseだけのスキーマの値
+	always *bool
+	ref    *Schema
+
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	minimum          interface{}
+	maximum          interface{}
+	exclusiveMinimum interface{}
+	exclusiveMaximum interface{}
+	multipleOf       interface{}
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	prefixItems []*Schema
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+	contains    *Schema
+	minContains *int
+	maxContains *int
+
+	properties           map[string]*Schema
+	patternProperties    []patternSchema
+	additionalProperties *Schema
+	propertyNames        *Schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*Schema
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+	if_   *Schema
+	then  *Schema
+	else_ *Schema
+}
+
+type patternSchema struct {
+	source  string
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+// Compiler はパース済みのスキーマの文書をSchemaにする
+type Compiler struct {
+	doc    interface{}
+	loader func(uri string) (interface{}, error)
+
+	docs     map[string]interface{}
+	anchors  map[string]map[string]pointer.Pointer
+	compiled map[string]*Schema
+}
+
+type Option func(*Compiler)
+
+// WithLoader は他の文書を参照する$refのために文書を読み込む関数を指定する
+// fnには$refの`#`より前の部分がそのまま渡される
+func WithLoader(fn func(uri string) (interface{}, error)) Option {
+	return func(c *Compiler) {
+		c.loader = fn
+	}
+}
+
+func NewCompiler(doc interface{}, opts ...Option) *Compiler {
+	c := &Compiler{
+		doc: doc,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Compile はスキーマの文書をコンパイルする
+func Compile(doc interface{}, opts ...Option) (*Schema, error) {
+	return NewCompiler(doc, opts...).Execute()
+}
+
+func (c *Compiler) Execute() (*Schema, error) {
+	c.docs = map[string]interface{}{}
+	c.anchors = map[string]map[string]pointer.Pointer{}
+	c.compiled = map[string]*Schema{}
+	c.addDocument("", c.doc)
+	return c.compile("", pointer.Pointer{}, c.doc)
+}
+
+func (c *Compiler) addDocument(uri string, doc interface{}) {
+	c.docs[uri] = doc
+	anchors := map[string]pointer.Pointer{}
+	scanAnchors(doc, pointer.Pointer{}, anchors)
+	c.anchors[uri] = anchors
+}
+
+// scanAnchors は$anchorで名前を付けられたスキーマの位置を集める
+func scanAnchors(v interface{}, path pointer.Pointer, anchors map[string]pointer.Pointer) {
+	switch val := v.(type) {
+	case value.Object:
+		if name, ok := val["$anchor"].(value.String); ok {
+			anchors[string(name)] = path
+		}
+		for k, vi := range val {
+			// enumとconstの中身はスキーマではない
+			if k == "enum" || k == "const" {
+				continue
+			}
+			scanAnchors(vi, path.Append(k), anchors)
+		}
+	case value.Array:
+		for i, vi := range val {
+			scanAnchors(vi, path.AppendIndex(i), anchors)
+		}
+	}
+}
+
+func (c *Compiler) errorf(path pointer.Pointer, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at %q", ErrInvalidSchema, fmt.Sprintf(format, args...), path.String())
+}
+
+func (c *Compiler) compile(uri string, path pointer.Pointer, v interface{}) (*Schema, error) {
+	key := uri + "#" + path.String()
+	if s, ok := c.compiled[key]; ok {
+		return s, nil
+	}
+	s := &Schema{}
+	// 循環する$refのために中身を埋める前に登録する
+	c.compiled[key] = s
+
+	switch val := v.(type) {
+	case value.Bool:
+		b := bool(val)
+		s.always = &b
+		return s, nil
+	case value.Object:
+		if err := c.compileObject(uri, path, val, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, c.errorf(path, "schema must be an object or a boolean")
+}
+
+func (c *Compiler) compileObject(uri string, path pointer.Pointer, o value.Object, s *Schema) error {
+	var err error
+	sub := func(keyword string) (*Schema, error) {
+		v, ok := o[keyword]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(uri, path.Append(keyword), v)
+	}
+	list := func(keyword string) ([]*Schema, error) {
+		v, ok := o[keyword]
+		if !ok {
+			return nil, nil
+		}
+		a, ok := v.(value.Array)
+		if !ok || len(a) == 0 {
+			return nil, c.errorf(path.Append(keyword), "%s must be a non-empty array", keyword)
+		}
+		schemas := make([]*Schema, len(a))
+		for i, vi := range a {
+			if schemas[i], err = c.compile(uri, path.Append(keyword).AppendIndex(i), vi); err != nil {
+				return nil, err
+			}
+		}
+		return schemas, nil
+	}
+	schemaMap := func(keyword string) (map[string]*Schema, error) {
+		v, ok := o[keyword]
+		if !ok {
+			return nil, nil
+		}
+		m, ok := v.(value.Object)
+		if !ok {
+			return nil, c.errorf(path.Append(keyword), "%s must be an object", keyword)
+		}
+		schemas := make(map[string]*Schema, len(m))
+		for k, vi := range m {
+			if schemas[k], err = c.compile(uri, path.Append(keyword, k), vi); err != nil {
+				return nil, err
+			}
+		}
+		return schemas, nil
+	}
+	number := func(keyword string) (interface{}, error) {
+		v, ok := o[keyword]
+		if !ok {
+			return nil, nil
+		}
+		if value.KindOf(v) != value.KindNumber {
+			return nil, c.errorf(path.Append(keyword), "%s must be a number", keyword)
+		}
+		return v, nil
+	}
+	count := func(keyword string) (*int, error) {
+		v, ok := o[keyword]
+		if !ok {
+			return nil, nil
+		}
+		n, ok := toInt(v)
+		if !ok || n < 0 {
+			return nil, c.errorf(path.Append(keyword), "%s must be a non-negative integer", keyword)
+		}
+		i := int(n)
+		return &i, nil
+	}
+	compilePattern := func(p pointer.Pointer, v interface{}) (*regexp.Regexp, error) {
+		src, ok := v.(value.String)
+		if !ok {
+			return nil, c.errorf(p, "pattern must be a string")
+		}
+		re, err := regexp.Compile(string(src))
+		if err != nil {
+			return nil, c.errorf(p, "invalid pattern %q", string(src))
+		}
+		return re, nil
+	}
+
+	if v, ok := o["$ref"]; ok {
+		ref, ok := v.(value.String)
+		if !ok {
+			return c.errorf(path.Append("$ref"), "$ref must be a string")
+		}
+		if s.ref, err = c.resolve(uri, path.Append("$ref"), string(ref)); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := o["type"]; ok {
+		switch t := v.(type) {
+		case value.String:
+			s.types = []string{string(t)}
+		case value.Array:
+			for _, ti := range t {
+				name, ok := ti.(value.String)
+				if !ok {
+					return c.errorf(path.Append("type"), "type must be a string or an array of strings")
+				}
+				s.types = append(s.types, string(name))
+			}
+		default:
+			return c.errorf(path.Append("type"), "type must be a string or an array of strings")
+		}
+		for _, t := range s.types {
+			if !validTypes[t] {
+				return c.errorf(path.Append("type"), "unknown type %q", t)
+			}
+		}
+	}
+	if v, ok := o["enum"]; ok {
+		a, ok := v.(value.Array)
+		if !ok {
+			return c.errorf(path.Append("enum"), "enum must be an array")
+		}
+		s.enum = a
+	}
+	if v, ok := o["const"]; ok {
+		s.hasConst, s.constVal = true, v
+	}
+
+	if s.minimum, err = number("minimum"); err != nil {
+		return err
+	}
+	if s.maximum, err = number("maximum"); err != nil {
+		return err
+	}
+	if s.exclusiveMinimum, err = number("exclusiveMinimum"); err != nil {
+		return err
+	}
+	if s.exclusiveMaximum, err = number("exclusiveMaximum"); err != nil {
+		return err
+	}
+	if s.multipleOf, err = number("multipleOf"); err != nil {
+		return err
+	}
+	if s.multipleOf != nil && toFloat(s.multipleOf) <= 0 {
+		return c.errorf(path.Append("multipleOf"), "multipleOf must be greater than 0")
+	}
+
+	if s.minLength, err = count("minLength"); err != nil {
+		return err
+	}
+	if s.maxLength, err = count("maxLength"); err != nil {
+		return err
+	}
+	if v, ok := o["pattern"]; ok {
+		if s.pattern, err = compilePattern(path.Append("pattern"), v); err != nil {
+			return err
+		}
+	}
+	if v, ok := o["format"]; ok {
+		f, ok := v.(value.String)
+		if !ok {
+			return c.errorf(path.Append("format"), "format must be a string")
+		}
+		s.format = string(f)
+	}
+
+	if s.prefixItems, err = list("prefixItems"); err != nil {
+		return err
+	}
+	if s.items, err = sub("items"); err != nil {
+		return err
+	}
+	if s.minItems, err = count("minItems"); err != nil {
+		return err
+	}
+	if s.maxItems, err = count("maxItems"); err != nil {
+		return err
+	}
+	if v, ok := o["uniqueItems"]; ok {
+		b, ok := v.(value.Bool)
+		if !ok {
+			return c.errorf(path.Append("uniqueItems"), "uniqueItems must be a boolean")
+		}
+		s.uniqueItems = bool(b)
+	}
+	if s.contains, err = sub("contains"); err != nil {
+		return err
+	}
+	if s.minContains, err = count("minContains"); err != nil {
+		return err
+	}
+	if s.maxContains, err = count("maxContains"); err != nil {
+		return err
+	}
+
+	if s.properties, err = schemaMap("properties"); err != nil {
+		return err
+	}
+	if v, ok := o["patternProperties"]; ok {
+		m, ok := v.(value.Object)
+		if !ok {
+			return c.errorf(path.Append("patternProperties"), "patternProperties must be an object")
+		}
+		for _, k := range value.SortedKeys(m) {
+			p := path.Append("patternProperties", k)
+			re, err := compilePattern(p, value.String(k))
+			if err != nil {
+				return err
+			}
+			ps, err := c.compile(uri, p, m[k])
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{source: k, pattern: re, schema: ps})
+		}
+	}
+	if s.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return err
+	}
+	if s.propertyNames, err = sub("propertyNames"); err != nil {
+		return err
+	}
+	if v, ok := o["required"]; ok {
+		if s.required, err = c.stringList(path.Append("required"), v); err != nil {
+			return err
+		}
+	}
+	if s.minProperties, err = count("minProperties"); err != nil {
+		return err
+	}
+	if s.maxProperties, err = count("maxProperties"); err != nil {
+		return err
+	}
+	if v, ok := o["dependentRequired"]; ok {
+		m, ok := v.(value.Object)
+		if !ok {
+			return c.errorf(path.Append("dependentRequired"), "dependentRequired must be an object")
+		}
+		s.dependentRequired = map[string][]string{}
+		for k, vi := range m {
+			if s.dependentRequired[k], err = c.stringList(path.Append("dependentRequired", k), vi); err != nil {
+				return err
+			}
+		}
+	}
+	if s.dependentSchemas, err = schemaMap("dependentSchemas"); err != nil {
+		return err
+	}
+
+	if s.allOf, err = list("allOf"); err != nil {
+		return err
+	}
+	if s.anyOf, err = list("anyOf"); err != nil {
+		return err
+	}
+	if s.oneOf, err = list("oneOf"); err != nil {
+		return err
+	}
+	if s.not, err = sub("not"); err != nil {
+		return err
+	}
+	if s.if_, err = sub("if"); err != nil {
+		return err
+	}
+	if s.then, err = sub("then"); err != nil {
+		return err
+	}
+	if s.else_, err = sub("else"); err != nil {
+		return err
+	}
+
+	// $defsは参照されたときにコンパイルされるが、誤りは先に見つけておく
+	for _, keyword := range []string{"$defs", "definitions"} {
+		if _, err := schemaMap(keyword); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) stringList(path pointer.Pointer, v interface{}) ([]string, error) {
+	a, ok := v.(value.Array)
+	if !ok {
+		return nil, c.errorf(path, "must be an array of strings")
+	}
+	list := make([]string, len(a))
+	for i, vi := range a {
+		s, ok := vi.(value.String)
+		if !ok {
+			return nil, c.errorf(path, "must be an array of strings")
+		}
+		list[i] = string(s)
+	}
+	return list, nil
+}
+
+// resolve は$refが指すスキーマを探してコンパイルする
+// `#`の後ろはJSON Pointerか$anchorの名前として解釈し、前に何かあればWithLoaderで読み込んだ文書から探す
+func (c *Compiler) resolve(uri string, path pointer.Pointer, ref string) (*Schema, error) {
+	base, fragment := ref, ""
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		base, fragment = ref[:i], ref[i+1:]
+	}
+	if base != "" {
+		if _, ok := c.docs[base]; !ok {
+			if c.loader == nil {
+				return nil, fmt.Errorf("%w: %q at %q", ErrUnsupportedRef, ref, path.String())
+			}
+			doc, err := c.loader(base)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q at %q: %v", ErrUnresolvedRef, ref, path.String(), err)
+			}
+			c.addDocument(base, doc)
+		}
+		uri = base
+	}
+
+	var target pointer.Pointer
+	if fragment == "" || fragment[0] == '/' {
+		unescaped, err := url.PathUnescape(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q at %q", ErrUnresolvedRef, ref, path.String())
+		}
+		if target, err = pointer.Parse(unescaped); err != nil {
+			return nil, fmt.Errorf("%w: %q at %q", ErrUnresolvedRef, ref, path.String())
+		}
+	} else {
+		p, ok := c.anchors[uri][fragment]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q at %q", ErrUnresolvedRef, ref, path.String())
+		}
+		target = p
+	}
+	v, err := value.GetIn(c.docs[uri], target)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q at %q", ErrUnresolvedRef, ref, path.String())
+	}
+	return c.compile(uri, target, v)
+}
+
+var validTypes = map[string]bool{
+	"null": true, "boolean": true, "integer": true, "number": true,
+	"string": true, "array": true, "object": true,
+}
@@ -0,0 +1,465 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+)
+
+func parse(t *testing.T, input string) interface{} {
+	t.Helper()
+	tokens, err := lexer.NewLexer(input).Execute()
+	if err != nil {
+		t.Fatalf("failed to tokenize %#v", err)
+	}
+	v, err := parser.NewParser(*tokens).Execute()
+	if err != nil {
+		t.Fatalf("failed to parse %#v", err)
+	}
+	return v
+}
+
+// failure は検証エラーの位置
+type failure struct {
+	Instance string
+	Schema   string
+}
+
+func failures(err error) []failure {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	got := []failure{}
+	for _, e := range errs {
+		got = append(got, failure{Instance: e.InstancePath.String(), Schema: e.SchemaPath.String()})
+	}
+	return got
+}
+
+const configSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "port"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"port": {"type": "integer", "minimum": 1, "exclusiveMaximum": 65536},
+		"ratio": {"type": "number", "multipleOf": 0.1},
+		"mode": {"enum": ["dev", "prod"]},
+		"version": {"const": 2},
+		"owner": {"$ref": "#/$defs/email"},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3},
+		"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+		"tree": {"$ref": "#node"}
+	},
+	"patternProperties": {"^x-": {"type": "string"}},
+	"additionalProperties": false,
+	"dependentRequired": {"ratio": ["mode"]},
+	"$defs": {
+		"email": {"type": "string", "format": "email"},
+		"node": {
+			"$anchor": "node",
+			"type": "object",
+			"properties": {"children": {"type": "array", "items": {"$ref": "#node"}}}
+		}
+	}
+}`
+
+func TestSuccessValidate(t *testing.T) {
+	s, err := Compile(parse(t, configSchema))
+	if err != nil {
+		t.Fatalf("failed to compile %#v", err)
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []failure
+	}{
+		{
+			name:  "すべて満たす",
+			input: `{"name": "app", "port": 8080.0, "ratio": 0.3, "mode": "dev", "version": 2.0, "owner": "a@example.com", "tags": ["a", "b"], "point": [1, 2.5], "tree": {"children": [{"children": []}]}, "x-note": "n"}`,
+			want:  nil,
+		},
+		{
+			name:  "すべてのエラーを報告する",
+			input: `{"name": "App-Name-Too-Long", "port": 65536, "ratio": 0.25, "owner": "not email", "tags": ["a", "a", 1, "b"], "point": [1, 2, 3], "tree": {"children": [1]}, "x-note": 1, "extra": true}`,
+			want: []failure{
+				{Instance: "", Schema: "/dependentRequired/ratio"},
+				{Instance: "/extra", Schema: "/additionalProperties"},
+				{Instance: "/name", Schema: "/properties/name/maxLength"},
+				{Instance: "/name", Schema: "/properties/name/pattern"},
+				{Instance: "/owner", Schema: "/properties/owner/$ref/format"},
+				{Instance: "/point/2", Schema: "/properties/point/items"},
+				{Instance: "/port", Schema: "/properties/port/exclusiveMaximum"},
+				{Instance: "/ratio", Schema: "/properties/ratio/multipleOf"},
+				{Instance: "/tags", Schema: "/properties/tags/maxItems"},
+				{Instance: "/tags", Schema: "/properties/tags/uniqueItems"},
+				{Instance: "/tags/2", Schema: "/properties/tags/items/type"},
+				{Instance: "/tree/children/0", Schema: "/properties/tree/$ref/properties/children/items/$ref/type"},
+				{Instance: "/x-note", Schema: "/patternProperties/^x-/type"},
+			},
+		},
+		{
+			name:  "ルートの型が違う",
+			input: `[]`,
+			want: []failure{
+				{Instance: "", Schema: "/type"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := failures(s.Validate(parse(t, tt.input)))
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSuccessValidateCombinators(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   []failure
+	}{
+		{
+			name:   "allOfは全てのエラーを報告する",
+			schema: `{"allOf": [{"type": "string"}, {"minLength": 2}]}`,
+			input:  `1`,
+			want:   []failure{{Instance: "", Schema: "/allOf/0/type"}},
+		},
+		{
+			name:   "anyOf",
+			schema: `{"anyOf": [{"type": "string"}, {"type": "null"}]}`,
+			input:  `true`,
+			want:   []failure{{Instance: "", Schema: "/anyOf"}},
+		},
+		{
+			name:   "oneOfに複数一致する",
+			schema: `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`,
+			input:  `1`,
+			want:   []failure{{Instance: "", Schema: "/oneOf"}},
+		},
+		{
+			name:   "not",
+			schema: `{"not": {"const": "x"}}`,
+			input:  `"x"`,
+			want:   []failure{{Instance: "", Schema: "/not"}},
+		},
+		{
+			name:   "ifに一致するとthenを検証する",
+			schema: `{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["a"]}, "else": {"required": ["b"]}}`,
+			input:  `{"kind": "a"}`,
+			want:   []failure{{Instance: "", Schema: "/then/required"}},
+		},
+		{
+			name:   "ifに一致しないとelseを検証する",
+			schema: `{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["a"]}, "else": {"required": ["b"]}}`,
+			input:  `{"kind": "b", "b": 1}`,
+			want:   nil,
+		},
+		{
+			name:   "containsとpropertyNames",
+			schema: `{"contains": {"type": "integer"}, "maxContains": 1, "propertyNames": {"maxLength": 1}}`,
+			input:  `[1, 2, "a"]`,
+			want:   []failure{{Instance: "", Schema: "/maxContains"}},
+		},
+		{
+			name:   "整数の最大値と同じ小数",
+			schema: `{"maximum": 1, "minimum": 1}`,
+			input:  `1.0`,
+			want:   nil,
+		},
+		{
+			name:   "小数の最小値と同じ整数",
+			schema: `{"minimum": 0.0, "maximum": 0.0}`,
+			input:  `0`,
+			want:   nil,
+		},
+		{
+			name:   "小数の排他的な最大値と同じ整数",
+			schema: `{"exclusiveMaximum": 1.0, "exclusiveMinimum": 1.0}`,
+			input:  `1`,
+			want:   []failure{{Instance: "", Schema: "/exclusiveMinimum"}, {Instance: "", Schema: "/exclusiveMaximum"}},
+		},
+		{
+			name:   "整数の排他的な範囲の中の小数",
+			schema: `{"exclusiveMinimum": 1, "exclusiveMaximum": 2}`,
+			input:  `1.5`,
+			want:   nil,
+		},
+		{
+			name:   "自分自身だけを参照する$ref",
+			schema: `{"$ref": "#"}`,
+			input:  `{"a": 1}`,
+			want:   []failure{{Instance: "", Schema: "/$ref/$ref"}},
+		},
+		{
+			name:   "定義の間で循環する$ref",
+			schema: `{"$ref": "#/$defs/a", "$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"allOf": [{"$ref": "#/$defs/a"}]}}}`,
+			input:  `1`,
+			want:   []failure{{Instance: "", Schema: "/$ref/$ref/allOf/0/$ref"}},
+		},
+		{
+			name:   "値を読み進める再帰的な$refは循環ではない",
+			schema: `{"type": ["integer", "array"], "items": {"$ref": "#"}, "propertyNames": {"$ref": "#"}}`,
+			input:  `[1, [2, [3]]]`,
+			want:   nil,
+		},
+		{
+			name:   "キーをルートのスキーマで検証する",
+			schema: `{"propertyNames": {"$ref": "#"}, "maxLength": 2}`,
+			input:  `{"ab": 1, "abc": 2}`,
+			want:   []failure{{Instance: "", Schema: "/propertyNames/$ref/maxLength"}},
+		},
+		{
+			name:   "falseのスキーマ",
+			schema: `{"properties": {"a": false}}`,
+			input:  `{"a": 1}`,
+			want:   []failure{{Instance: "/a", Schema: "/properties/a"}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := Compile(parse(t, tt.schema))
+			if err != nil {
+				t.Fatalf("failed to compile %#v", err)
+			}
+			got := failures(s.Validate(parse(t, tt.input)))
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSuccessFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  []string
+		broken []string
+	}{
+		{format: "date-time", valid: []string{"2022-05-01T12:00:00Z", "2022-05-01t12:00:00.5+09:00"}, broken: []string{"2022-05-01"}},
+		{format: "date", valid: []string{"2022-05-01"}, broken: []string{"2022-13-01"}},
+		{format: "time", valid: []string{"12:00:00Z"}, broken: []string{"25:00:00Z"}},
+		{format: "email", valid: []string{"a@example.com"}, broken: []string{"a"}},
+		{format: "hostname", valid: []string{"example.com"}, broken: []string{"-a.com"}},
+		{format: "ipv4", valid: []string{"127.0.0.1"}, broken: []string{"::1"}},
+		{format: "ipv6", valid: []string{"::1"}, broken: []string{"127.0.0.1"}},
+		{format: "uri", valid: []string{"https://example.com/a"}, broken: []string{"/a"}},
+		{format: "uuid", valid: []string{"123e4567-e89b-12d3-a456-426614174000"}, broken: []string{"123"}},
+		{format: "json-pointer", valid: []string{"/a/~0"}, broken: []string{"a"}},
+	}
+	for _, tt := range tests {
+		check := formats[tt.format]
+		for _, s := range tt.valid {
+			if !check(s) {
+				t.Errorf("%s: want %q to be valid", tt.format, s)
+			}
+		}
+		for _, s := range tt.broken {
+			if check(s) {
+				t.Errorf("%s: want %q to be invalid", tt.format, s)
+			}
+		}
+	}
+}
+
+func TestSuccessLoader(t *testing.T) {
+	docs := map[string]string{
+		"common.json": `{"$defs": {"id": {"type": "integer", "minimum": 1}}}`,
+	}
+	s, err := Compile(parse(t, `{"properties": {"id": {"$ref": "common.json#/$defs/id"}}}`), WithLoader(func(uri string) (interface{}, error) {
+		return parse(t, docs[uri]), nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to compile %#v", err)
+	}
+	got := failures(s.Validate(parse(t, `{"id": 0}`)))
+	want := []failure{{Instance: "/id", Schema: "/properties/id/$ref/minimum"}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailedCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   error
+	}{
+		{name: "スキーマが配列", schema: `[]`, want: ErrInvalidSchema},
+		{name: "未知の型", schema: `{"type": "int"}`, want: ErrInvalidSchema},
+		{name: "負の長さ", schema: `{"minLength": -1}`, want: ErrInvalidSchema},
+		{name: "不正な正規表現", schema: `{"pattern": "("}`, want: ErrInvalidSchema},
+		{name: "$defsの中の誤り", schema: `{"$defs": {"a": 1}}`, want: ErrInvalidSchema},
+		{name: "存在しない参照", schema: `{"$ref": "#/$defs/none"}`, want: ErrUnresolvedRef},
+		{name: "存在しないanchor", schema: `{"$ref": "#none"}`, want: ErrUnresolvedRef},
+		{name: "他の文書の参照", schema: `{"$ref": "other.json"}`, want: ErrUnsupportedRef},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Compile(parse(t, tt.schema))
+			if got != nil {
+				t.Errorf("want error %v, but got result %v", tt.want, got)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	s, err := Compile(parse(t, `{"properties": {"a": {"type": ["string", "null"]}}}`))
+	if err != nil {
+		t.Fatalf("failed to compile %#v", err)
+	}
+	err = s.Validate(parse(t, `{"a": 1}`))
+	want := `expected string or null, but got integer at "/a" (schema "/properties/a/type")`
+	if err == nil || err.Error() != want {
+		t.Errorf("want %s, but got %v", want, err)
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.InstancePath.String() != "/a" {
+		t.Errorf("want ValidationError at /a, but got %v", err)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("want ErrValidation, but got %v", err)
+	}
+	if errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("want not ErrInvalidSchema, but got %v", err)
+	}
+}
+
+func TestSuccessInfer(t *testing.T) {
+	samples := []interface{}{
+		parse(t, `{"id": 1, "status": "active", "score": 1, "tags": ["a"], "owner": {"name": "a"}, "note": null}`),
+		parse(t, `{"id": 2, "status": "inactive", "score": 2.5, "tags": [], "owner": {"name": "b", "age": 3}}`),
+		parse(t, `{"id": 3, "status": "active", "score": -1, "tags": ["b", "c"], "owner": {"name": "c"}, "note": "n"}`),
+	}
+	got, err := NewInferrer(samples).Execute()
+	if err != nil {
+		t.Fatalf("failed to infer %#v", err)
+	}
+	want := parse(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["id", "owner", "score", "status", "tags"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1, "maximum": 3},
+			"status": {"type": "string", "enum": ["active", "inactive"]},
+			"score": {"type": "number", "minimum": -1, "maximum": 2.5},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"owner": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string"},
+					"age": {"type": "integer", "minimum": 3, "maximum": 3}
+				}
+			},
+			"note": {"type": ["string", "null"]}
+		}
+	}`)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+
+	// 推測したスキーマはサンプルを全て満たす
+	s, err := Compile(got)
+	if err != nil {
+		t.Fatalf("failed to compile %#v", err)
+	}
+	for _, sample := range samples {
+		if err := s.Validate(sample); err != nil {
+			t.Errorf("want valid, but got %v", err)
+		}
+	}
+}
+
+func TestSuccessInferEnumLimit(t *testing.T) {
+	samples := []interface{}{
+		parse(t, `["a", "b", "a", null]`),
+		parse(t, `["c"]`),
+	}
+	got, err := NewInferrer(samples, WithEnumLimit(2)).Execute()
+	if err != nil {
+		t.Fatalf("failed to infer %#v", err)
+	}
+	want := parse(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {"type": ["string", "null"]}
+	}`)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+
+	got, err = NewInferrer(samples, WithEnumLimit(3)).Execute()
+	if err != nil {
+		t.Fatalf("failed to infer %#v", err)
+	}
+	want = parse(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {"type": ["string", "null"], "enum": ["a", "b", "c", null]}
+	}`)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSuccessInferValidatesSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []string
+	}{
+		{name: "文字列と整数", samples: []string{`{"a": "x"}`, `{"a": "x"}`, `{"a": 1}`}},
+		{name: "文字列とbool", samples: []string{`["x", "x", true]`}},
+		{name: "文字列とobjectと配列", samples: []string{`["x", "x", {"b": 1}, [2]]`}},
+		{name: "文字列とnull", samples: []string{`["x", "x", null]`}},
+		{name: "整数と小数", samples: []string{`[1, 2.5]`, `[-3]`}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			samples := []interface{}{}
+			for _, in := range tt.samples {
+				samples = append(samples, parse(t, in))
+			}
+			doc, err := NewInferrer(samples).Execute()
+			if err != nil {
+				t.Fatalf("failed to infer %#v", err)
+			}
+			s, err := Compile(doc)
+			if err != nil {
+				t.Fatalf("failed to compile %#v", err)
+			}
+			// 推測したスキーマはサンプルを全て満たす
+			for _, sample := range samples {
+				if err := s.Validate(sample); err != nil {
+					t.Errorf("want valid, but got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFailedInfer(t *testing.T) {
+	if _, err := NewInferrer(nil).Execute(); !errors.Is(err, ErrNoSample) {
+		t.Fatalf("want ErrNoSample, but got %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/printer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+// ValidationError はインスタンスがスキーマを満たさない箇所を表す
+type ValidationError struct {
+	// InstancePath は検証した値の位置
+	InstancePath pointer.Pointer
+	// SchemaPath は満たさなかったキーワードの位置
+	// $refをたどった場合は$refを含む評価した経路になる
+	SchemaPath pointer.Pointer
+	Message    string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s at %q (schema %q)", e.Message, e.InstancePath.String(), e.SchemaPath.String())
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Errors は検証で見つかったすべてのエラー
+// errors.Isとerrors.Asはいずれかのエラーに一致すれば成功する
+type Errors []*ValidationError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// visit は検証中の$refの参照先とインスタンスの位置の組
+type visit struct {
+	schema   *Schema
+	instance string
+}
+
+// Validate はvがスキーマを満たすか検証する
+// 満たさない場合は見つかったすべての箇所をErrorsで返す
+func (s *Schema) Validate(v interface{}) error {
+	errs := s.validate(v, pointer.Pointer{}, pointer.Pointer{}, map[visit]bool{})
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (s *Schema) validate(v interface{}, inst, sp pointer.Pointer, active map[visit]bool) Errors {
+	errs := Errors{}
+	fail := func(keyword string, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			InstancePath: inst,
+			SchemaPath:   sp.Append(keyword),
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			errs = append(errs, &ValidationError{InstancePath: inst, SchemaPath: sp, Message: "no value is allowed"})
+		}
+		return errs
+	}
+	if s.ref != nil {
+		key := visit{schema: s.ref, instance: inst.String()}
+		if active[key] {
+			// インスタンスを読み進めずに同じ$refに戻ってきたので、たどり続けると終わらない
+			fail("$ref", "$ref refers to itself for the same value")
+		} else {
+			active[key] = true
+			errs = append(errs, s.ref.validate(v, inst, sp.Append("$ref"), active)...)
+			delete(active, key)
+		}
+	}
+
+	if len(s.types) > 0 {
+		ok := false
+		for _, t := range s.types {
+			if matchType(t, v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("type", "expected %s, but got %s", strings.Join(s.types, " or "), typeName(v))
+		}
+	}
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if value.Equal(v, e, value.WithNumericEquivalence()) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("enum", "value must be one of %s", format(value.Array(s.enum)))
+		}
+	}
+	if s.hasConst && !value.Equal(v, s.constVal, value.WithNumericEquivalence()) {
+		fail("const", "value must be %s", format(s.constVal))
+	}
+
+	switch val := v.(type) {
+	case value.NumberInt, value.NumberFloat:
+		s.validateNumber(val, fail)
+	case value.String:
+		s.validateString(string(val), fail)
+	case value.Array:
+		errs = append(errs, s.validateArray(val, inst, sp, active, fail)...)
+	case value.Object:
+		errs = append(errs, s.validateObject(val, inst, sp, active, fail)...)
+	}
+
+	for i, sub := range s.allOf {
+		errs = append(errs, sub.validate(v, inst, sp.Append("allOf").AppendIndex(i), active)...)
+	}
+	if s.anyOf != nil {
+		ok := false
+		for i, sub := range s.anyOf {
+			if len(sub.validate(v, inst, sp.Append("anyOf").AppendIndex(i), active)) == 0 {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("anyOf", "value must match at least one schema")
+		}
+	}
+	if s.oneOf != nil {
+		matched := []int{}
+		for i, sub := range s.oneOf {
+			if len(sub.validate(v, inst, sp.Append("oneOf").AppendIndex(i), active)) == 0 {
+				matched = append(matched, i)
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			fail("oneOf", "value must match exactly one schema, but matched none")
+		case len(matched) > 1:
+			fail("oneOf", "value must match exactly one schema, but matched %d", len(matched))
+		}
+	}
+	if s.not != nil && len(s.not.validate(v, inst, sp.Append("not"), active)) == 0 {
+		fail("not", "value must not match the schema")
+	}
+	if s.if_ != nil {
+		if len(s.if_.validate(v, inst, sp.Append("if"), active)) == 0 {
+			if s.then != nil {
+				errs = append(errs, s.then.validate(v, inst, sp.Append("then"), active)...)
+			}
+		} else if s.else_ != nil {
+			errs = append(errs, s.else_.validate(v, inst, sp.Append("else"), active)...)
+		}
+	}
+	return errs
+}
+
+func (s *Schema) validateNumber(v interface{}, fail func(string, string, ...interface{})) {
+	if s.minimum != nil && compareNumber(v, s.minimum) < 0 {
+		fail("minimum", "value must be >= %s", format(s.minimum))
+	}
+	if s.maximum != nil && compareNumber(v, s.maximum) > 0 {
+		fail("maximum", "value must be <= %s", format(s.maximum))
+	}
+	if s.exclusiveMinimum != nil && compareNumber(v, s.exclusiveMinimum) <= 0 {
+		fail("exclusiveMinimum", "value must be > %s", format(s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && compareNumber(v, s.exclusiveMaximum) >= 0 {
+		fail("exclusiveMaximum", "value must be < %s", format(s.exclusiveMaximum))
+	}
+	if s.multipleOf != nil && !isMultipleOf(v, s.multipleOf) {
+		fail("multipleOf", "value must be a multiple of %s", format(s.multipleOf))
+	}
+}
+
+func (s *Schema) validateString(v string, fail func(string, string, ...interface{})) {
+	// 長さはコードポイントの数で数える
+	length := utf8.RuneCountInString(v)
+	if s.minLength != nil && length < *s.minLength {
+		fail("minLength", "length must be >= %d, but got %d", *s.minLength, length)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		fail("maxLength", "length must be <= %d, but got %d", *s.maxLength, length)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		fail("pattern", "value must match pattern %q", s.pattern.String())
+	}
+	if check, ok := formats[s.format]; ok && !check(v) {
+		fail("format", "value must be a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateArray(a value.Array, inst, sp pointer.Pointer, active map[visit]bool, fail func(string, string, ...interface{})) Errors {
+	errs := Errors{}
+	if s.minItems != nil && len(a) < *s.minItems {
+		fail("minItems", "array must have at least %d items, but got %d", *s.minItems, len(a))
+	}
+	if s.maxItems != nil && len(a) > *s.maxItems {
+		fail("maxItems", "array must have at most %d items, but got %d", *s.maxItems, len(a))
+	}
+	if s.uniqueItems {
+		seen := map[uint64][]int{}
+		for i, vi := range a {
+			h := value.Hash(vi, value.WithNumericEquivalence())
+			for _, j := range seen[h] {
+				if value.Equal(a[j], vi, value.WithNumericEquivalence()) {
+					fail("uniqueItems", "items at %d and %d must be unique", j, i)
+					break
+				}
+			}
+			seen[h] = append(seen[h], i)
+		}
+	}
+	for i, vi := range a {
+		if i < len(s.prefixItems) {
+			errs = append(errs, s.prefixItems[i].validate(vi, inst.AppendIndex(i), sp.Append("prefixItems").AppendIndex(i), active)...)
+		} else if s.items != nil {
+			errs = append(errs, s.items.validate(vi, inst.AppendIndex(i), sp.Append("items"), active)...)
+		}
+	}
+	if s.contains != nil {
+		matched := 0
+		for i, vi := range a {
+			if len(s.contains.validate(vi, inst.AppendIndex(i), sp.Append("contains"), active)) == 0 {
+				matched++
+			}
+		}
+		minCount := 1
+		if s.minContains != nil {
+			minCount = *s.minContains
+		}
+		if matched < minCount {
+			fail("contains", "array must contain at least %d matching items, but got %d", minCount, matched)
+		}
+		if s.maxContains != nil && matched > *s.maxContains {
+			fail("maxContains", "array must contain at most %d matching items, but got %d", *s.maxContains, matched)
+		}
+	}
+	return errs
+}
+
+func (s *Schema) validateObject(o value.Object, inst, sp pointer.Pointer, active map[visit]bool, fail func(string, string, ...interface{})) Errors {
+	errs := Errors{}
+	if s.minProperties != nil && len(o) < *s.minProperties {
+		fail("minProperties", "object must have at least %d properties, but got %d", *s.minProperties, len(o))
+	}
+	if s.maxProperties != nil && len(o) > *s.maxProperties {
+		fail("maxProperties", "object must have at most %d properties, but got %d", *s.maxProperties, len(o))
+	}
+	for _, k := range s.required {
+		if _, ok := o[k]; !ok {
+			fail("required", "missing required property %q", k)
+		}
+	}
+	for _, k := range sortedKeys(s.dependentRequired) {
+		if _, ok := o[k]; !ok {
+			continue
+		}
+		for _, dep := range s.dependentRequired[k] {
+			if _, ok := o[dep]; !ok {
+				errs = append(errs, &ValidationError{
+					InstancePath: inst,
+					SchemaPath:   sp.Append("dependentRequired", k),
+					Message:      fmt.Sprintf("property %q is required when %q is present", dep, k),
+				})
+			}
+		}
+	}
+
+	for _, k := range value.SortedKeys(o) {
+		vi := o[k]
+		if s.propertyNames != nil {
+			// キーはobjectと同じ位置で検証するが別の値なので、objectの$refとは区別する
+			for _, e := range s.propertyNames.validate(value.String(k), inst, sp.Append("propertyNames"), map[visit]bool{}) {
+				e.Message = fmt.Sprintf("property name %q: %s", k, e.Message)
+				errs = append(errs, e)
+			}
+		}
+		evaluated := false
+		if ps, ok := s.properties[k]; ok {
+			evaluated = true
+			errs = append(errs, ps.validate(vi, inst.Append(k), sp.Append("properties", k), active)...)
+		}
+		for _, pp := range s.patternProperties {
+			if pp.pattern.MatchString(k) {
+				evaluated = true
+				errs = append(errs, pp.schema.validate(vi, inst.Append(k), sp.Append("patternProperties", pp.source), active)...)
+			}
+		}
+		if !evaluated && s.additionalProperties != nil {
+			errs = append(errs, s.additionalProperties.validate(vi, inst.Append(k), sp.Append("additionalProperties"), active)...)
+		}
+		if ds, ok := s.dependentSchemas[k]; ok {
+			errs = append(errs, ds.validate(o, inst, sp.Append("dependentSchemas", k), active)...)
+		}
+	}
+	return errs
+}
+
+func matchType(t string, v interface{}) bool {
+	switch t {
+	case "null":
+		return value.IsNull(v)
+	case "boolean":
+		_, ok := v.(value.Bool)
+		return ok
+	case "integer":
+		_, ok := toInt(v)
+		return ok
+	case "number":
+		return value.KindOf(v) == value.KindNumber
+	case "string":
+		_, ok := v.(value.String)
+		return ok
+	case "array":
+		_, ok := v.(value.Array)
+		return ok
+	case "object":
+		_, ok := v.(value.Object)
+		return ok
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case value.NumberInt:
+		return "integer"
+	case value.Bool:
+		return "boolean"
+	}
+	return value.KindOf(v).String()
+}
+
+// compareNumber は数値を値で比較する
+// value.CompareはNumberIntとNumberFloatの値が同じ場合も区別するので、数値として等しければ0にする
+func compareNumber(a, b interface{}) int {
+	if value.Equal(a, b, value.WithNumericEquivalence()) {
+		return 0
+	}
+	return value.Compare(a, b)
+}
+
+// toInt は小数部のない数値を整数にする
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case value.NumberInt:
+		return int64(n), true
+	case value.NumberFloat:
+		f := float64(n)
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case value.NumberInt:
+		return float64(n)
+	case value.NumberFloat:
+		return float64(n)
+	}
+	return math.NaN()
+}
+
+func isMultipleOf(v, m interface{}) bool {
+	vi, vok := v.(value.NumberInt)
+	mi, mok := m.(value.NumberInt)
+	if vok && mok {
+		return vi%mi == 0
+	}
+	q := toFloat(v) / toFloat(m)
+	if math.IsInf(q, 0) || math.IsNaN(q) {
+		return false
+	}
+	// 0.1の倍数のような小数の誤差を許す
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func format(v interface{}) string {
+	var b strings.Builder
+	if err := printer.NewPrinter(v, printer.WithWriter(&b)).Execute(); err != nil {
+		return fmt.Sprint(v)
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,282 @@
+package stream
+
+import (
+	"errors"
+	"io"
+
+	"github.com/sam8helloworld/json-go/decoder"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/token"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+var (
+	ErrNotArray = errors.New("value is not an array")
+)
+
+// ArrayReader は巨大な配列の要素を1つずつ読む
+// 入力は少しずつ読み、一度に持つのは1つの要素のトークンだけなので、
+// 使うメモリは入力全体ではなく最も大きい要素の大きさに比例する
+// 配列の後ろは読まないので、配列より後ろの誤りは報告しない
+type ArrayReader struct {
+	lexer *lexer.Lexer
+	path  pointer.Pointer
+	// peeked はseekで先読みして戻したトークン
+	peeked token.Token
+
+	started bool
+	first   bool
+	done    bool
+	err     error
+}
+
+type Option func(*ArrayReader)
+
+// WithPointer は文書の中でpathの位置にある配列を読む
+// 指定しない場合は文書全体が配列でなければならない
+func WithPointer(path pointer.Pointer) Option {
+	return func(a *ArrayReader) {
+		a.path = path
+	}
+}
+
+func NewArrayReader(r io.Reader, opts ...Option) *ArrayReader {
+	a := &ArrayReader{
+		lexer: lexer.NewReaderLexer(r),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Next は次の要素をパースして返す
+// 要素を読み終えたらio.EOFを返す
+func (a *ArrayReader) Next() (interface{}, error) {
+	tokens, err := a.nextElement()
+	if err != nil {
+		return nil, err
+	}
+	v, err := parser.NewParser(tokens).Execute()
+	if err != nil {
+		a.err = err
+		return nil, err
+	}
+	return v, nil
+}
+
+// Decode は次の要素をvが指す値に格納する
+// 要素を読み終えたらio.EOFを返す
+func (a *ArrayReader) Decode(v interface{}, opts ...decoder.Option) error {
+	elem, err := a.Next()
+	if err != nil {
+		return err
+	}
+	return decoder.NewDecoder(elem, opts...).Execute(v)
+}
+
+// nextElement は次の要素のトークンを集める
+func (a *ArrayReader) nextElement() ([]token.Token, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	if a.done {
+		return nil, io.EOF
+	}
+	if !a.started {
+		a.started = true
+		if err := a.seek(); err != nil {
+			a.err = err
+			return nil, err
+		}
+		a.first = true
+	}
+
+	t, err := a.next()
+	if err != nil {
+		a.err = err
+		return nil, err
+	}
+	if !a.first {
+		// 前の要素の後ろは,か]
+		switch t.(type) {
+		case token.CommaToken:
+			if t, err = a.next(); err != nil {
+				a.err = err
+				return nil, err
+			}
+		case token.RightBracketToken:
+			a.done = true
+			return nil, io.EOF
+		default:
+			a.err = parser.ErrParse
+			return nil, a.err
+		}
+	} else if _, ok := t.(token.RightBracketToken); ok {
+		a.done = true
+		return nil, io.EOF
+	}
+	a.first = false
+
+	tokens, err := a.collect(t)
+	if err != nil {
+		a.err = err
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// seek はpathの位置にある配列の[まで読み進める
+func (a *ArrayReader) seek() error {
+	for _, key := range a.path {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.LeftBraceToken:
+			err = a.seekKey(key)
+		case token.LeftBracketToken:
+			err = a.seekIndex(key)
+		default:
+			err = value.ErrPathNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	if _, ok := t.(token.LeftBracketToken); !ok {
+		return ErrNotArray
+	}
+	return nil
+}
+
+func (a *ArrayReader) seekKey(key string) error {
+	for {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		k, ok := t.(token.StringToken)
+		if !ok {
+			if _, ok := t.(token.RightBraceToken); ok {
+				return value.ErrPathNotFound
+			}
+			return parser.ErrInvalidKeyValuePair
+		}
+		if t, err = a.next(); err != nil {
+			return err
+		}
+		if _, ok := t.(token.ColonToken); !ok {
+			return parser.ErrInvalidKeyValuePair
+		}
+		if k.Value() == key {
+			return nil
+		}
+		if err := a.skipValue(); err != nil {
+			return err
+		}
+		if t, err = a.next(); err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.CommaToken:
+			continue
+		case token.RightBraceToken:
+			return value.ErrPathNotFound
+		}
+		return parser.ErrParse
+	}
+}
+
+func (a *ArrayReader) seekIndex(key string) error {
+	index, ok := pointer.Index(key)
+	if !ok {
+		return value.ErrInvalidIndex
+	}
+	for i := 0; i < index; i++ {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		if _, ok := t.(token.RightBracketToken); ok {
+			return value.ErrPathNotFound
+		}
+		if _, err := a.collect(t); err != nil {
+			return err
+		}
+		if t, err = a.next(); err != nil {
+			return err
+		}
+		switch t.(type) {
+		case token.CommaToken:
+			continue
+		case token.RightBracketToken:
+			return value.ErrPathNotFound
+		}
+		return parser.ErrParse
+	}
+	// 添字の位置で配列が終わっていれば範囲外
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	if _, ok := t.(token.RightBracketToken); ok {
+		return value.ErrPathNotFound
+	}
+	a.peeked = t
+	return nil
+}
+
+func (a *ArrayReader) skipValue() error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	_, err = a.collect(t)
+	return err
+}
+
+// collect はtから始まる1つの値のトークンを、括弧が閉じるまで集める
+// 値として正しいかはparser.Parserが検査する
+func (a *ArrayReader) collect(t token.Token) ([]token.Token, error) {
+	tokens := []token.Token{t}
+	depth := 0
+	for {
+		switch t.(type) {
+		case token.LeftBraceToken, token.LeftBracketToken:
+			depth++
+		case token.RightBraceToken, token.RightBracketToken:
+			depth--
+		}
+		if depth <= 0 {
+			return tokens, nil
+		}
+		var err error
+		if t, err = a.next(); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// next は次のトークンを返す
+// 配列の途中で入力が終わった場合はparser.ErrParseにする
+func (a *ArrayReader) next() (token.Token, error) {
+	if a.peeked != nil {
+		t := a.peeked
+		a.peeked = nil
+		return t, nil
+	}
+	t, err := a.lexer.Next()
+	if err == io.EOF {
+		return nil, parser.ErrParse
+	}
+	return t, err
+}
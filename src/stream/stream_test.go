@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/decoder"
+	"github.com/sam8helloworld/json-go/lexer"
+	"github.com/sam8helloworld/json-go/parser"
+	"github.com/sam8helloworld/json-go/pointer"
+	"github.com/sam8helloworld/json-go/value"
+)
+
+func readAll(a *ArrayReader) ([]interface{}, error) {
+	got := []interface{}{}
+	for {
+		v, err := a.Next()
+		if err == io.EOF {
+			return got, nil
+		}
+		if err != nil {
+			return got, err
+		}
+		got = append(got, v)
+	}
+}
+
+func TestSuccessNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  pointer.Pointer
+		want  []interface{}
+	}{
+		{
+			name:  "ルートの配列",
+			input: `[{"id": 1}, [2, {"x": []}], "3", null]`,
+			want: []interface{}{
+				value.Object{"id": value.NumberInt(1)},
+				value.Array{value.NumberInt(2), value.Object{"x": value.Array{}}},
+				value.String("3"),
+				value.Null,
+			},
+		},
+		{
+			name:  "空の配列",
+			input: ` [ ] `,
+			want:  []interface{}{},
+		},
+		{
+			name:  "JSON Pointerで指定した配列",
+			input: `{"meta": {"items": [0]}, "data": [{"skip": true}, {"items": [true, false]}], "items": [9]}`,
+			path:  pointer.Pointer{"data", "1", "items"},
+			want:  []interface{}{value.Bool(true), value.Bool(false)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readAll(NewArrayReader(strings.NewReader(tt.input), WithPointer(tt.path)))
+			if err != nil {
+				t.Fatalf("failed to read %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSuccessDecode(t *testing.T) {
+	type record struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	a := NewArrayReader(strings.NewReader(`[{"id": 1, "name": "a"}, {"id": "x"}, {"id": 3, "name": "c"}]`))
+	got := []record{}
+	for {
+		var r record
+		err := a.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+		// 変換できない要素があっても続きを読める
+		var te *decoder.TypeError
+		if errors.As(err, &te) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to decode %#v", err)
+		}
+		got = append(got, r)
+	}
+	want := []record{{ID: 1, Name: "a"}, {ID: 3, Name: "c"}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  pointer.Pointer
+		got   int
+		want  error
+	}{
+		{name: "配列ではない", input: `{"a": 1}`, want: ErrNotArray},
+		{name: "キーがない", input: `{"a": [1]}`, path: pointer.Pointer{"b"}, want: value.ErrPathNotFound},
+		{name: "添字が範囲外", input: `[[1]]`, path: pointer.Pointer{"1"}, want: value.ErrPathNotFound},
+		{name: "空の配列の添字", input: `{"a": []}`, path: pointer.Pointer{"a", "0"}, want: value.ErrPathNotFound},
+		{name: "空の配列の中の配列の添字", input: `[]`, path: pointer.Pointer{"0", "0"}, want: value.ErrPathNotFound},
+		{name: "不正な添字", input: `[[1]]`, path: pointer.Pointer{"01"}, want: value.ErrInvalidIndex},
+		{name: "途中で入力が終わる", input: `[1, 2, {"a": `, got: 2, want: parser.ErrParse},
+		{name: "要素の区切りがない", input: `[1 2]`, got: 1, want: parser.ErrParse},
+		{name: "不正な要素", input: `[1, {"a" 1}, 3]`, got: 1, want: parser.ErrInvalidKeyValuePair},
+		{name: "字句解析のエラー", input: `[true, nul]`, got: 1, want: lexer.ErrNullTokenize},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := NewArrayReader(strings.NewReader(tt.input), WithPointer(tt.path))
+			got, err := readAll(a)
+			if len(got) != tt.got {
+				t.Errorf("want %d elements before the error, but got %v", tt.got, got)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("want %v, but got %v", tt.want, err)
+			}
+			// エラーの後は同じエラーを返し続ける
+			if _, err := a.Next(); !errors.Is(err, tt.want) {
+				t.Fatalf("want %v again, but got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package token
+
+// CommentToken はJSONCの//か/* */のコメント
+// valueは//や/* */を含むコメントのテキスト
+type CommentToken struct {
+	Token
+	value string
+}
+
+func NewCommentToken(value string) CommentToken {
+	return CommentToken{
+		value: value,
+	}
+}
+
+func (ct *CommentToken) Value() string {
+	return ct.value
+}
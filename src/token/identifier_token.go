@@ -0,0 +1,18 @@
+package token
+
+// IdentifierToken はJSON5のクォートしていない識別子
+// objectのキーか、InfinityとNaNに使う
+type IdentifierToken struct {
+	Token
+	value string
+}
+
+func NewIdentifierToken(value string) IdentifierToken {
+	return IdentifierToken{
+		value: value,
+	}
+}
+
+func (it *IdentifierToken) Value() string {
+	return it.value
+}
@@ -0,0 +1,6 @@
+package token
+
+// RecordSeparatorToken はJSON text sequence (RFC 7464) のレコードの区切り (RS, 0x1E)
+type RecordSeparatorToken struct {
+	Token
+}
@@ -0,0 +1,8 @@
+package token
+
+// Span はトークンが入力のどこにあったかを表す
+// Startは先頭の文字、Endは末尾の次の文字のインデックスで、どちらもrune単位
+type Span struct {
+	Start int
+	End   int
+}
@@ -0,0 +1,115 @@
+package value
+
+// ObjectBuilder はObjectを組み立てる
+//
+//	NewObjectBuilder().
+//		String("name", "json-go").
+//		Array("tags", NewArrayBuilder().String("go").String("json")).
+//		Build()
+type ObjectBuilder struct {
+	object Object
+}
+
+func NewObjectBuilder() *ObjectBuilder {
+	return &ObjectBuilder{object: Object{}}
+}
+
+// Set はkeyにこのパッケージの型の値を設定する
+// ObjectBuilderとArrayBuilderを渡すと組み立てた値を設定する
+func (b *ObjectBuilder) Set(key string, v interface{}) *ObjectBuilder {
+	b.object[key] = build(v)
+	return b
+}
+
+func (b *ObjectBuilder) String(key string, s string) *ObjectBuilder {
+	return b.Set(key, String(s))
+}
+
+func (b *ObjectBuilder) Int(key string, i int64) *ObjectBuilder {
+	return b.Set(key, NumberInt(i))
+}
+
+func (b *ObjectBuilder) Float(key string, f float64) *ObjectBuilder {
+	return b.Set(key, NumberFloat(f))
+}
+
+func (b *ObjectBuilder) Bool(key string, v bool) *ObjectBuilder {
+	return b.Set(key, Bool(v))
+}
+
+func (b *ObjectBuilder) Null(key string) *ObjectBuilder {
+	return b.Set(key, Null)
+}
+
+func (b *ObjectBuilder) Object(key string, o *ObjectBuilder) *ObjectBuilder {
+	return b.Set(key, o)
+}
+
+func (b *ObjectBuilder) Array(key string, a *ArrayBuilder) *ObjectBuilder {
+	return b.Set(key, a)
+}
+
+// Build は組み立てたObjectを返す
+// 返した後にBuilderを使い続けても返したObjectには影響しない
+func (b *ObjectBuilder) Build() Object {
+	return DeepCopy(b.object).(Object)
+}
+
+// ArrayBuilder はArrayを組み立てる
+type ArrayBuilder struct {
+	array Array
+}
+
+func NewArrayBuilder() *ArrayBuilder {
+	return &ArrayBuilder{array: Array{}}
+}
+
+// Add は末尾に値を追加する
+// ObjectBuilderとArrayBuilderを渡すと組み立てた値を追加する
+func (b *ArrayBuilder) Add(v interface{}) *ArrayBuilder {
+	b.array = append(b.array, build(v))
+	return b
+}
+
+func (b *ArrayBuilder) String(s string) *ArrayBuilder {
+	return b.Add(String(s))
+}
+
+func (b *ArrayBuilder) Int(i int64) *ArrayBuilder {
+	return b.Add(NumberInt(i))
+}
+
+func (b *ArrayBuilder) Float(f float64) *ArrayBuilder {
+	return b.Add(NumberFloat(f))
+}
+
+func (b *ArrayBuilder) Bool(v bool) *ArrayBuilder {
+	return b.Add(Bool(v))
+}
+
+func (b *ArrayBuilder) Null() *ArrayBuilder {
+	return b.Add(Null)
+}
+
+func (b *ArrayBuilder) Object(o *ObjectBuilder) *ArrayBuilder {
+	return b.Add(o)
+}
+
+func (b *ArrayBuilder) Array(a *ArrayBuilder) *ArrayBuilder {
+	return b.Add(a)
+}
+
+// Build は組み立てたArrayを返す
+func (b *ArrayBuilder) Build() Array {
+	return DeepCopy(b.array).(Array)
+}
+
+func build(v interface{}) interface{} {
+	switch b := v.(type) {
+	case *ObjectBuilder:
+		return b.Build()
+	case *ArrayBuilder:
+		return b.Build()
+	}
+	return v
+}
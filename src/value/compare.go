@@ -0,0 +1,129 @@
+package value
+
+import (
+	"math"
+	"strings"
+)
+
+// Compare は任意の2つの値の全順序を定める
+// aがbより小さければ-1、等しければ0、大きければ1を返す
+// 種類が異なる場合はnull < bool < number < string < array < object < Rawの順になる
+// Rawどうしはテキストを辞書順に比較する
+// 数値はNumberIntとNumberFloatを跨いで値で比較し、値が同じならNumberIntを小さいとみなす
+func Compare(a, b interface{}) int {
+	ak, bk := KindOf(a), KindOf(b)
+	if ak != bk {
+		return compareInt(int(ak), int(bk))
+	}
+	switch av := a.(type) {
+	case Bool:
+		bv := b.(Bool)
+		switch {
+		case av == bv:
+			return 0
+		case !bool(av):
+			return -1
+		}
+		return 1
+	case NumberInt:
+		switch bv := b.(type) {
+		case NumberInt:
+			return compareInt64(int64(av), int64(bv))
+		case NumberFloat:
+			if r := compareIntFloat(int64(av), float64(bv)); r != 0 {
+				return r
+			}
+			return -1
+		}
+	case NumberFloat:
+		switch bv := b.(type) {
+		case NumberFloat:
+			return compareFloat(float64(av), float64(bv))
+		case NumberInt:
+			if r := compareIntFloat(int64(bv), float64(av)); r != 0 {
+				return -r
+			}
+			return 1
+		}
+	case String:
+		return strings.Compare(string(av), string(b.(String)))
+	case Raw:
+		return strings.Compare(string(av), string(b.(Raw)))
+	case Array:
+		bv := b.(Array)
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if r := Compare(av[i], bv[i]); r != 0 {
+				return r
+			}
+		}
+		return compareInt(len(av), len(bv))
+	case Object:
+		// キーをソートした(キー, 値)の列として辞書順に比較する
+		bv := b.(Object)
+		ak, bk := SortedKeys(av), SortedKeys(bv)
+		for i := 0; i < len(ak) && i < len(bk); i++ {
+			if r := strings.Compare(ak[i], bk[i]); r != 0 {
+				return r
+			}
+			if r := Compare(av[ak[i]], bv[bk[i]]); r != 0 {
+				return r
+			}
+		}
+		return compareInt(len(ak), len(bk))
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	return compareInt64(int64(a), int64(b))
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloat はNaNを全ての数値より小さいものとして比較する
+func compareFloat(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return -1
+	case math.IsNaN(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareIntFloat はfloat64に変換せずに精度を落とさないで比較する
+func compareIntFloat(i int64, f float64) int {
+	switch {
+	case math.IsNaN(f):
+		return 1
+	case f >= math.MaxInt64:
+		return -1
+	case f < math.MinInt64:
+		return 1
+	}
+	t := math.Trunc(f)
+	if r := compareInt64(i, int64(t)); r != 0 {
+		return r
+	}
+	switch {
+	case f > t:
+		return -1
+	case f < t:
+		return 1
+	}
+	return 0
+}
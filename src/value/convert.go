@@ -0,0 +1,161 @@
+package value
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/sam8helloworld/json-go/internal/structfield"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrOverflow        = errors.New("number overflows int64")
+)
+
+// FromGo はGoの値をこのパッケージの値に変換する
+// nilはNull、整数はNumberInt、小数はNumberFloat、mapと構造体はObject、スライスと配列はArrayになる
+// 構造体のフィールドは`json`タグのキー名、`-`、`omitempty`、`string`に従う
+// NullはGoではint型の0なのでNumberInt(0)になる。Nullにしたい場合はnilを渡す
+func FromGo(v interface{}) (interface{}, error) {
+	return fromGo(reflect.ValueOf(v))
+}
+
+var rawType = reflect.TypeOf(Raw(""))
+
+func fromGo(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return Null, nil
+	}
+	if rv.Type() == rawType {
+		return Raw(rv.String()), nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return Bool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NumberInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return nil, ErrOverflow
+		}
+		return NumberInt(u), nil
+	case reflect.Float32, reflect.Float64:
+		return NumberFloat(rv.Float()), nil
+	case reflect.String:
+		return String(rv.String()), nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		return fromGo(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return Null, nil
+		}
+		array := make(Array, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			vi, err := fromGo(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			array[i] = vi
+		}
+		return array, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		object := make(Object, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			vi, err := fromGo(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			object[k] = vi
+		}
+		return object, nil
+	case reflect.Struct:
+		object := Object{}
+		for _, f := range structfield.Fields(rv.Type()) {
+			fv, ok := structfield.Value(rv, f, false)
+			if !ok || (f.OmitEmpty && structfield.IsEmpty(fv)) {
+				continue
+			}
+			vi, err := fromGo(fv)
+			if err != nil {
+				return nil, err
+			}
+			if f.Quoted {
+				vi = quote(vi)
+			}
+			object[f.Name] = vi
+		}
+		return object, nil
+	}
+	return nil, ErrUnsupportedType
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// quote は`string`オプションのために数値と真偽値を文字列にする
+func quote(v interface{}) interface{} {
+	switch val := v.(type) {
+	case NumberInt:
+		return String(strconv.FormatInt(int64(val), 10))
+	case NumberFloat:
+		return String(strconv.FormatFloat(float64(val), 'g', -1, 64))
+	case Bool:
+		return String(strconv.FormatBool(bool(val)))
+	}
+	return v
+}
+
+// ToGo はこのパッケージの値をGoの組み込みの型に変換する
+// Objectはmap[string]interface{}、Arrayは[]interface{}、NumberIntはint64、NumberFloatはfloat64、Nullはnilになる
+// Rawはパースせずにそのまま返す
+func ToGo(v interface{}) interface{} {
+	switch val := v.(type) {
+	case String:
+		return string(val)
+	case NumberInt:
+		return int64(val)
+	case NumberFloat:
+		return float64(val)
+	case Bool:
+		return bool(val)
+	case Raw:
+		// FromGoと対になるようRawのまま返す
+		return val
+	case Array:
+		array := make([]interface{}, len(val))
+		for i, vi := range val {
+			array[i] = ToGo(vi)
+		}
+		return array
+	case Object:
+		object := make(map[string]interface{}, len(val))
+		for k, vi := range val {
+			object[k] = ToGo(vi)
+		}
+		return object
+	}
+	return nil
+}
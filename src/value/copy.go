@@ -0,0 +1,24 @@
+package value
+
+// DeepCopy は配列とobjectを再帰的に複製した値を返す
+// 複製した値を変更しても元の値には影響しない
+func DeepCopy(v interface{}) interface{} {
+	switch val := v.(type) {
+	case Raw:
+		// Rawは文字列なので複製しなくても共有されない
+		return val
+	case Array:
+		array := make(Array, len(val))
+		for i, vi := range val {
+			array[i] = DeepCopy(vi)
+		}
+		return array
+	case Object:
+		object := make(Object, len(val))
+		for k, vi := range val {
+			object[k] = DeepCopy(vi)
+		}
+		return object
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package value
+
+type equalConfig struct {
+	numericEquivalence bool
+	ignoreArrayOrder   bool
+}
+
+type EqualOption func(*equalConfig)
+
+// WithNumericEquivalence はNumberIntとNumberFloatを数値として比較する
+// NumberInt(1)とNumberFloat(1.0)は等しいとみなす
+func WithNumericEquivalence() EqualOption {
+	return func(c *equalConfig) {
+		c.numericEquivalence = true
+	}
+}
+
+// WithIgnoreArrayOrder は配列の要素の順序を無視して比較する
+func WithIgnoreArrayOrder() EqualOption {
+	return func(c *equalConfig) {
+		c.ignoreArrayOrder = true
+	}
+}
+
+func newEqualConfig(opts []EqualOption) *equalConfig {
+	c := &equalConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Equal はaとbが同じ値かどうかを再帰的に比較する
+// Rawはパースせず、同じテキストのRawとだけ等しい
+func Equal(a, b interface{}, opts ...EqualOption) bool {
+	return equal(a, b, newEqualConfig(opts))
+}
+
+func equal(a, b interface{}, c *equalConfig) bool {
+	switch av := a.(type) {
+	case NumberInt:
+		switch bv := b.(type) {
+		case NumberInt:
+			return av == bv
+		case NumberFloat:
+			return c.numericEquivalence && compareIntFloat(int64(av), float64(bv)) == 0
+		}
+		return false
+	case NumberFloat:
+		switch bv := b.(type) {
+		case NumberFloat:
+			return av == bv
+		case NumberInt:
+			return c.numericEquivalence && compareIntFloat(int64(bv), float64(av)) == 0
+		}
+		return false
+	case Bool:
+		bv, ok := b.(Bool)
+		return ok && av == bv
+	case String:
+		bv, ok := b.(String)
+		return ok && av == bv
+	case Raw:
+		bv, ok := b.(Raw)
+		return ok && av == bv
+	case Array:
+		bv, ok := b.(Array)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		if c.ignoreArrayOrder {
+			return equalUnordered(av, bv, c)
+		}
+		for i := range av {
+			if !equal(av[i], bv[i], c) {
+				return false
+			}
+		}
+		return true
+	case Object:
+		bv, ok := b.(Object)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, vi := range av {
+			bi, ok := bv[k]
+			if !ok || !equal(vi, bi, c) {
+				return false
+			}
+		}
+		return true
+	}
+	return IsNull(a) && IsNull(b)
+}
+
+// equalUnordered は要素を多重集合とみなして比較する
+func equalUnordered(a, b Array, c *equalConfig) bool {
+	matched := make([]bool, len(b))
+	for _, ai := range a {
+		found := false
+		for j, bj := range b {
+			if !matched[j] && equal(ai, bj, c) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
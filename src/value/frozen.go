@@ -0,0 +1,89 @@
+package value
+
+import "github.com/sam8helloworld/json-go/pointer"
+
+// Frozen は変更できない値のビュー
+// 内部の値はどこからも変更されないので、複数のgoroutineから同時に読み取っても安全
+// SetInやDeleteInは元のFrozenと構造を共有した新しいFrozenを返す
+type Frozen struct {
+	v interface{}
+}
+
+// Freeze はvを複製して読み取り専用にする
+// 複製後にvを変更してもFrozenには影響しない
+func Freeze(v interface{}) Frozen {
+	return Frozen{v: DeepCopy(v)}
+}
+
+func (f Frozen) Kind() Kind {
+	return KindOf(f.v)
+}
+
+// Len は配列の要素数、objectのキー数を返す
+// それ以外の値では0を返す
+func (f Frozen) Len() int {
+	switch val := f.v.(type) {
+	case Array:
+		return len(val)
+	case Object:
+		return len(val)
+	}
+	return 0
+}
+
+// Index は配列のi番目の要素を返す
+func (f Frozen) Index(i int) (Frozen, bool) {
+	array, ok := f.v.(Array)
+	if !ok || i < 0 || i >= len(array) {
+		return Frozen{}, false
+	}
+	return Frozen{v: array[i]}, true
+}
+
+// Get はobjectのキーの値を返す
+func (f Frozen) Get(key string) (Frozen, bool) {
+	object, ok := f.v.(Object)
+	if !ok {
+		return Frozen{}, false
+	}
+	v, ok := object[key]
+	return Frozen{v: v}, ok
+}
+
+// Keys はobjectのキーをソートして返す
+func (f Frozen) Keys() []string {
+	object, ok := f.v.(Object)
+	if !ok {
+		return nil
+	}
+	return SortedKeys(object)
+}
+
+func (f Frozen) GetIn(path pointer.Pointer) (Frozen, error) {
+	v, err := GetIn(f.v, path)
+	if err != nil {
+		return Frozen{}, err
+	}
+	return Frozen{v: v}, nil
+}
+
+func (f Frozen) SetIn(path pointer.Pointer, v interface{}) (Frozen, error) {
+	nv, err := SetIn(f.v, path, DeepCopy(v))
+	if err != nil {
+		return Frozen{}, err
+	}
+	return Frozen{v: nv}, nil
+}
+
+func (f Frozen) DeleteIn(path pointer.Pointer) (Frozen, error) {
+	nv, err := DeleteIn(f.v, path)
+	if err != nil {
+		return Frozen{}, err
+	}
+	return Frozen{v: nv}, nil
+}
+
+// Value は変更可能な複製を返す
+func (f Frozen) Value() interface{} {
+	return DeepCopy(f.v)
+}
@@ -0,0 +1,97 @@
+package value
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// Hash は値の内容から安定したハッシュ値を計算する
+// Equalで等しい値は同じハッシュ値になる
+// Equalと同じオプションを渡すと、そのオプションで等しい値も同じハッシュ値になる
+func Hash(v interface{}, opts ...EqualOption) uint64 {
+	return hash(v, newEqualConfig(opts))
+}
+
+const (
+	hashTagNull byte = iota
+	hashTagFalse
+	hashTagTrue
+	hashTagInt
+	hashTagFloat
+	hashTagString
+	hashTagArray
+	hashTagObject
+	hashTagRaw
+)
+
+func hash(v interface{}, c *equalConfig) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	writeUint64 := func(u uint64) {
+		binary.BigEndian.PutUint64(buf, u)
+		h.Write(buf)
+	}
+	writeString := func(s string) {
+		// 長さを先に書いて区切りを曖昧にしない
+		writeUint64(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+
+	switch val := v.(type) {
+	case Bool:
+		if val {
+			h.Write([]byte{hashTagTrue})
+		} else {
+			h.Write([]byte{hashTagFalse})
+		}
+	case NumberInt:
+		h.Write([]byte{hashTagInt})
+		writeUint64(uint64(val))
+	case NumberFloat:
+		f := float64(val)
+		if c.numericEquivalence && f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			// 整数とみなせる小数はNumberIntと同じハッシュ値にする
+			h.Write([]byte{hashTagInt})
+			writeUint64(uint64(int64(f)))
+			break
+		}
+		if f == 0 {
+			// -0と0は等しいので区別しない
+			f = 0
+		}
+		h.Write([]byte{hashTagFloat})
+		writeUint64(math.Float64bits(f))
+	case String:
+		h.Write([]byte{hashTagString})
+		writeString(string(val))
+	case Raw:
+		h.Write([]byte{hashTagRaw})
+		writeString(string(val))
+	case Array:
+		h.Write([]byte{hashTagArray})
+		writeUint64(uint64(len(val)))
+		if c.ignoreArrayOrder {
+			// 順序に依存しないよう要素のハッシュ値の和を使う
+			var sum uint64
+			for _, vi := range val {
+				sum += hash(vi, c)
+			}
+			writeUint64(sum)
+			break
+		}
+		for _, vi := range val {
+			writeUint64(hash(vi, c))
+		}
+	case Object:
+		h.Write([]byte{hashTagObject})
+		writeUint64(uint64(len(val)))
+		for _, k := range SortedKeys(val) {
+			writeString(k)
+			writeUint64(hash(val[k], c))
+		}
+	default:
+		h.Write([]byte{hashTagNull})
+	}
+	return h.Sum64()
+}
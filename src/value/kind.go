@@ -0,0 +1,64 @@
+package value
+
+// Kind はJSONの値の種類を表す
+// 定義順はCompareでの並び順と一致する
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+	// KindRaw はパースしていないvalue.Raw
+	KindRaw
+	KindInvalid
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	case KindRaw:
+		return "raw"
+	}
+	return "invalid"
+}
+
+func KindOf(v interface{}) Kind {
+	switch v.(type) {
+	case Bool:
+		return KindBool
+	case NumberInt, NumberFloat:
+		return KindNumber
+	case String:
+		return KindString
+	case Array:
+		return KindArray
+	case Object:
+		return KindObject
+	case Raw:
+		return KindRaw
+	}
+	if IsNull(v) {
+		return KindNull
+	}
+	return KindInvalid
+}
+
+// IsNull はvがNullかどうかを返す
+func IsNull(v interface{}) bool {
+	i, ok := v.(int)
+	return ok && i == Null
+}
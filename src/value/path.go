@@ -0,0 +1,143 @@
+package value
+
+import (
+	"errors"
+
+	"github.com/sam8helloworld/json-go/pointer"
+)
+
+var (
+	ErrPathNotFound = errors.New("path not found")
+	ErrInvalidIndex = errors.New("invalid array index")
+)
+
+// GetIn はpathが指す値を返す
+func GetIn(root interface{}, path pointer.Pointer) (interface{}, error) {
+	v := root
+	for _, t := range path {
+		switch val := v.(type) {
+		case Object:
+			vi, ok := val[t]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			v = vi
+		case Array:
+			i, ok := pointer.Index(t)
+			if !ok {
+				return nil, ErrInvalidIndex
+			}
+			if i >= len(val) {
+				return nil, ErrPathNotFound
+			}
+			v = val[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return v, nil
+}
+
+// SetIn はpathにvを設定した新しい値を返す
+// pathの途中にある配列とobjectだけを複製し、それ以外は元の値と共有するのでrootは変更されない
+// objectにないキーは追加し、配列の末尾の次の添字か"-"を指定すると要素を追加する
+func SetIn(root interface{}, path pointer.Pointer, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	t := path[0]
+	switch val := root.(type) {
+	case Object:
+		child, ok := val[t]
+		if !ok && len(path) > 1 {
+			return nil, ErrPathNotFound
+		}
+		nv, err := SetIn(child, path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		object := make(Object, len(val)+1)
+		for k, vi := range val {
+			object[k] = vi
+		}
+		object[t] = nv
+		return object, nil
+	case Array:
+		i, ok := pointer.Index(t)
+		if t == "-" {
+			i, ok = len(val), true
+		}
+		if !ok || i > len(val) {
+			return nil, ErrInvalidIndex
+		}
+		if i == len(val) {
+			if len(path) > 1 {
+				return nil, ErrPathNotFound
+			}
+			array := make(Array, len(val), len(val)+1)
+			copy(array, val)
+			return append(array, v), nil
+		}
+		nv, err := SetIn(val[i], path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		array := make(Array, len(val))
+		copy(array, val)
+		array[i] = nv
+		return array, nil
+	}
+	return nil, ErrPathNotFound
+}
+
+// DeleteIn はpathの値を取り除いた新しい値を返す
+// SetInと同様にrootは変更されない
+func DeleteIn(root interface{}, path pointer.Pointer) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, ErrPathNotFound
+	}
+	t := path[0]
+	switch val := root.(type) {
+	case Object:
+		child, ok := val[t]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		object := make(Object, len(val))
+		for k, vi := range val {
+			object[k] = vi
+		}
+		if len(path) == 1 {
+			delete(object, t)
+			return object, nil
+		}
+		nv, err := DeleteIn(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		object[t] = nv
+		return object, nil
+	case Array:
+		i, ok := pointer.Index(t)
+		if !ok {
+			return nil, ErrInvalidIndex
+		}
+		if i >= len(val) {
+			return nil, ErrPathNotFound
+		}
+		if len(path) == 1 {
+			array := make(Array, 0, len(val)-1)
+			array = append(array, val[:i]...)
+			return append(array, val[i+1:]...), nil
+		}
+		nv, err := DeleteIn(val[i], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		array := make(Array, len(val))
+		copy(array, val)
+		array[i] = nv
+		return array, nil
+	}
+	return nil, ErrPathNotFound
+}
@@ -1,5 +1,7 @@
 package value
 
+import "sort"
+
 type String string
 type NumberInt int64
 type NumberFloat float64
@@ -9,3 +11,17 @@ const Null = iota
 
 type Array []interface{}
 type Object map[string]interface{}
+
+// Raw はパースせずに残した値のJSONのテキスト
+// 入力の文字列をそのまま保持し、printerはそのまま書き出す
+type Raw string
+
+// SortedKeys はobjectのキーをソートして返す
+func SortedKeys(o Object) []string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
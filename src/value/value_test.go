@@ -0,0 +1,664 @@
+package value
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sam8helloworld/json-go/pointer"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		opts []EqualOption
+		want bool
+	}{
+		{
+			name: "同じobject",
+			a:    Object{"a": Array{NumberInt(1), String("x")}, "b": Null},
+			b:    Object{"a": Array{NumberInt(1), String("x")}, "b": Null},
+			want: true,
+		},
+		{
+			name: "キーが異なるobject",
+			a:    Object{"a": NumberInt(1)},
+			b:    Object{"b": NumberInt(1)},
+			want: false,
+		},
+		{
+			name: "同じテキストのRaw",
+			a:    Object{"r": Raw(`{"a": 1}`)},
+			b:    Object{"r": Raw(`{"a": 1}`)},
+			want: true,
+		},
+		{
+			name: "テキストが異なるRawはパースした値が同じでも区別する",
+			a:    Raw(`{"a": 1}`),
+			b:    Raw(`{"a":1}`),
+			want: false,
+		},
+		{
+			name: "RawとStringは区別する",
+			a:    Raw(`"x"`),
+			b:    String(`"x"`),
+			want: false,
+		},
+		{
+			name: "NumberIntとNumberFloatは区別する",
+			a:    NumberInt(1),
+			b:    NumberFloat(1),
+			want: false,
+		},
+		{
+			name: "数値として比較",
+			a:    Array{NumberInt(1), NumberFloat(2)},
+			b:    Array{NumberFloat(1), NumberInt(2)},
+			opts: []EqualOption{WithNumericEquivalence()},
+			want: true,
+		},
+		{
+			name: "数値として比較しても小数部があれば異なる",
+			a:    NumberInt(1),
+			b:    NumberFloat(1.5),
+			opts: []EqualOption{WithNumericEquivalence()},
+			want: false,
+		},
+		{
+			name: "配列の順序が異なる",
+			a:    Array{NumberInt(1), NumberInt(2)},
+			b:    Array{NumberInt(2), NumberInt(1)},
+			want: false,
+		},
+		{
+			name: "配列の順序を無視",
+			a:    Array{NumberInt(1), NumberInt(2), NumberInt(2)},
+			b:    Array{NumberInt(2), NumberInt(1), NumberInt(2)},
+			opts: []EqualOption{WithIgnoreArrayOrder()},
+			want: true,
+		},
+		{
+			name: "配列の順序を無視しても要素数が異なれば異なる",
+			a:    Array{NumberInt(1), NumberInt(1), NumberInt(2)},
+			b:    Array{NumberInt(1), NumberInt(2), NumberInt(2)},
+			opts: []EqualOption{WithIgnoreArrayOrder()},
+			want: false,
+		},
+		{
+			name: "nullとfalseは異なる",
+			a:    Null,
+			b:    Bool(false),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Equal(tt.a, tt.b, tt.opts...); got != tt.want {
+				t.Errorf("want %t, but got %t", tt.want, got)
+			}
+			if got := Equal(tt.b, tt.a, tt.opts...); got != tt.want {
+				t.Errorf("want %t, but got %t (reversed)", tt.want, got)
+			}
+			// 等しい値のハッシュ値は一致する
+			if tt.want && Hash(tt.a, tt.opts...) != Hash(tt.b, tt.opts...) {
+				t.Errorf("equal values have different hashes")
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	a := Object{"a": Array{NumberInt(1), String("x")}, "b": NumberFloat(0)}
+	b := Object{"b": NumberFloat(math.Copysign(0, -1)), "a": Array{NumberInt(1), String("x")}}
+	if Hash(a) != Hash(b) {
+		t.Errorf("equal values have different hashes")
+	}
+	// 区切りが曖昧だと衝突しやすい組み合わせ
+	if Hash(Array{String("ab"), String("c")}) == Hash(Array{String("a"), String("bc")}) {
+		t.Errorf("different values have the same hash")
+	}
+	if Hash(Object{"a": Null}) == Hash(Object{"a": Bool(false)}) {
+		t.Errorf("different values have the same hash")
+	}
+	if Hash(Raw("1")) != Hash(Raw("1")) {
+		t.Errorf("equal raw values have different hashes")
+	}
+	for _, v := range []interface{}{Null, String("1"), NumberInt(1), Raw("2")} {
+		if Hash(Raw("1")) == Hash(v) {
+			t.Errorf("raw value has the same hash as %#v", v)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// 昇順に並べた値
+	sorted := []interface{}{
+		Null,
+		Bool(false),
+		Bool(true),
+		NumberFloat(math.NaN()),
+		NumberInt(-1),
+		NumberFloat(0.5),
+		NumberInt(1),
+		NumberFloat(1),
+		NumberFloat(1.5),
+		NumberInt(math.MaxInt64),
+		String(""),
+		String("a"),
+		String("b"),
+		Array{},
+		Array{NumberInt(1)},
+		Array{NumberInt(1), NumberInt(1)},
+		Array{NumberInt(2)},
+		Object{},
+		Object{"a": NumberInt(1)},
+		Object{"a": NumberInt(2)},
+		Object{"a": NumberInt(2), "b": NumberInt(0)},
+		Object{"b": NumberInt(0)},
+		Raw(`"a"`),
+		Raw(`1`),
+		Raw(`[]`),
+	}
+	for i := range sorted {
+		for j := range sorted {
+			want := compareInt(i, j)
+			if got := Compare(sorted[i], sorted[j]); got != want {
+				t.Errorf("Compare(%v, %v): want %d, but got %d", sorted[i], sorted[j], want, got)
+			}
+		}
+	}
+
+	shuffled := make([]interface{}, len(sorted))
+	for i := range sorted {
+		shuffled[i] = sorted[(i*7)%len(sorted)]
+	}
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return Compare(shuffled[i], shuffled[j]) < 0
+	})
+	if diff := cmp.Diff(shuffled, sorted, cmp.Comparer(func(a, b NumberFloat) bool {
+		return a == b || (math.IsNaN(float64(a)) && math.IsNaN(float64(b)))
+	})); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	got := SortedKeys(Object{"b": Null, "a": Null, "": Null, "あ": Null})
+	if diff := cmp.Diff(got, []string{"", "a", "b", "あ"}); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if got := SortedKeys(Object{}); len(got) != 0 {
+		t.Fatalf("want no keys, but got %v", got)
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	original := Object{"a": Array{Object{"b": NumberInt(1)}}, "r": Raw(`{"x": [1]}`)}
+	copied := DeepCopy(original).(Object)
+	if diff := cmp.Diff(copied, original); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	copied["a"].(Array)[0].(Object)["b"] = NumberInt(2)
+	copied["c"] = Null
+	want := Object{"a": Array{Object{"b": NumberInt(1)}}, "r": Raw(`{"x": [1]}`)}
+	if diff := cmp.Diff(original, want); diff != "" {
+		t.Fatalf("original was modified: (-got +want)\n%s", diff)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  Kind
+	}{
+		{input: Null, want: KindNull},
+		{input: Bool(true), want: KindBool},
+		{input: NumberFloat(1), want: KindNumber},
+		{input: String("x"), want: KindString},
+		{input: Array{}, want: KindArray},
+		{input: Object{}, want: KindObject},
+		{input: Raw(`[]`), want: KindRaw},
+		{input: 1.5, want: KindInvalid},
+	}
+	for _, tt := range tests {
+		if got := KindOf(tt.input); got != tt.want {
+			t.Errorf("KindOf(%#v): want %v, but got %v", tt.input, tt.want, got)
+		}
+	}
+	if got := KindRaw.String(); got != "raw" {
+		t.Errorf("want raw, but got %s", got)
+	}
+}
+
+func TestSetIn(t *testing.T) {
+	tests := []struct {
+		name string
+		path pointer.Pointer
+		v    interface{}
+		want interface{}
+	}{
+		{
+			name: "文書全体を置き換え",
+			path: pointer.Pointer{},
+			v:    NumberInt(1),
+			want: NumberInt(1),
+		},
+		{
+			name: "objectの値を置き換え",
+			path: pointer.Pointer{"a", "b"},
+			v:    String("x"),
+			want: Object{"a": Object{"b": String("x")}, "c": Array{NumberInt(1), NumberInt(2)}},
+		},
+		{
+			name: "objectにキーを追加",
+			path: pointer.Pointer{"a", "d"},
+			v:    Null,
+			want: Object{"a": Object{"b": NumberInt(0), "d": Null}, "c": Array{NumberInt(1), NumberInt(2)}},
+		},
+		{
+			name: "配列の要素を置き換え",
+			path: pointer.Pointer{"c", "1"},
+			v:    Bool(true),
+			want: Object{"a": Object{"b": NumberInt(0)}, "c": Array{NumberInt(1), Bool(true)}},
+		},
+		{
+			name: "配列の末尾に追加",
+			path: pointer.Pointer{"c", "-"},
+			v:    NumberInt(3),
+			want: Object{"a": Object{"b": NumberInt(0)}, "c": Array{NumberInt(1), NumberInt(2), NumberInt(3)}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := Object{"a": Object{"b": NumberInt(0)}, "c": Array{NumberInt(1), NumberInt(2)}}
+			got, err := SetIn(root, tt.path, tt.v)
+			if err != nil {
+				t.Fatalf("failed to set %#v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("got differs: (-got +want)\n%s", diff)
+			}
+			// 元の値は変更されない
+			original := Object{"a": Object{"b": NumberInt(0)}, "c": Array{NumberInt(1), NumberInt(2)}}
+			if diff := cmp.Diff(root, original); diff != "" {
+				t.Fatalf("original was modified: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetInSharesUntouchedNodes(t *testing.T) {
+	shared := Object{"x": NumberInt(1)}
+	root := Object{"a": shared, "b": Object{}}
+	got, err := SetIn(root, pointer.Pointer{"b", "y"}, NumberInt(2))
+	if err != nil {
+		t.Fatalf("failed to set %#v", err)
+	}
+	got.(Object)["a"].(Object)["z"] = NumberInt(3)
+	if _, ok := shared["z"]; !ok {
+		t.Errorf("untouched node was copied")
+	}
+}
+
+func TestFailedSetIn(t *testing.T) {
+	root := Object{"a": Array{NumberInt(1)}}
+	tests := []struct {
+		path pointer.Pointer
+		want error
+	}{
+		{pointer.Pointer{"x", "y"}, ErrPathNotFound},
+		{pointer.Pointer{"a", "5"}, ErrInvalidIndex},
+		{pointer.Pointer{"a", "01"}, ErrInvalidIndex},
+		{pointer.Pointer{"a", "0", "b"}, ErrPathNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := SetIn(root, tt.path, Null); !errors.Is(err, tt.want) {
+			t.Errorf("%s: want %v, but got %v", tt.path, tt.want, err)
+		}
+	}
+}
+
+func TestDeleteIn(t *testing.T) {
+	root := Object{"a": Array{NumberInt(1), NumberInt(2), NumberInt(3)}, "b": Null}
+	got, err := DeleteIn(root, pointer.Pointer{"a", "1"})
+	if err != nil {
+		t.Fatalf("failed to delete %#v", err)
+	}
+	got, err = DeleteIn(got, pointer.Pointer{"b"})
+	if err != nil {
+		t.Fatalf("failed to delete %#v", err)
+	}
+	want := Object{"a": Array{NumberInt(1), NumberInt(3)}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if len(root["a"].(Array)) != 3 || len(root) != 2 {
+		t.Errorf("original was modified: %v", root)
+	}
+	if _, err := DeleteIn(root, pointer.Pointer{"c"}); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("want ErrPathNotFound, but got %v", err)
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	v := Object{"list": Array{String("a"), String("b")}}
+	f := Freeze(v)
+	// 凍結後に元の値を変更しても影響しない
+	v["list"].(Array)[0] = String("z")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g, err := f.SetIn(pointer.Pointer{"list", "-"}, NumberInt(int64(i)))
+			if err != nil {
+				t.Errorf("failed to set %#v", err)
+				return
+			}
+			if g.Len() != 1 {
+				t.Errorf("want 1 key, but got %d", g.Len())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	list, ok := f.Get("list")
+	if !ok || list.Kind() != KindArray || list.Len() != 2 {
+		t.Fatalf("unexpected list %v", list.Value())
+	}
+	first, _ := list.Index(0)
+	if diff := cmp.Diff(first.Value(), String("a")); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	if diff := cmp.Diff(f.Keys(), []string{"list"}); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	tags := NewArrayBuilder().String("go").Int(1).Float(1.5).Bool(false).Null()
+	got := NewObjectBuilder().
+		String("name", "json-go").
+		Int("stars", 10).
+		Array("tags", tags).
+		Object("owner", NewObjectBuilder().Null("email").Bool("active", true)).
+		Set("raw", Array{}).
+		Build()
+	want := Object{
+		"name":  String("json-go"),
+		"stars": NumberInt(10),
+		"tags":  Array{String("go"), NumberInt(1), NumberFloat(1.5), Bool(false), Null},
+		"owner": Object{"email": Null, "active": Bool(true)},
+		"raw":   Array{},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	// Buildした後にBuilderを変更しても影響しない
+	tags.String("later")
+	if len(got["tags"].(Array)) != 5 {
+		t.Errorf("built value was modified")
+	}
+}
+
+type goUser struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Score   float64           `json:"score,string"`
+	Tags    []string          `json:"tags"`
+	Attrs   map[string]uint8  `json:"attrs"`
+	Parent  *goUser           `json:"parent"`
+	Ignored string            `json:"-"`
+	Extra   interface{}       `json:"extra"`
+	Nested  map[int][]float32 `json:"nested,omitempty"`
+}
+
+func TestFromGo(t *testing.T) {
+	got, err := FromGo(goUser{
+		Name:  "taro",
+		Score: 1.5,
+		Tags:  []string{"a"},
+		Attrs: map[string]uint8{"x": 1},
+		Extra: map[string]interface{}{"b": true, "n": nil},
+	})
+	if err != nil {
+		t.Fatalf("failed to convert %#v", err)
+	}
+	want := Object{
+		"name":   String("taro"),
+		"score":  String("1.5"),
+		"tags":   Array{String("a")},
+		"attrs":  Object{"x": NumberInt(1)},
+		"parent": Null,
+		"extra":  Object{"b": Bool(true), "n": Null},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+
+	if _, err := FromGo(map[string]interface{}{"f": func() {}}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("want ErrUnsupportedType, but got %v", err)
+	}
+	if _, err := FromGo(uint64(math.MaxUint64)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("want ErrOverflow, but got %v", err)
+	}
+}
+
+func TestToGo(t *testing.T) {
+	got := ToGo(Object{
+		"s": String("x"),
+		"i": NumberInt(1),
+		"f": NumberFloat(1.5),
+		"b": Bool(true),
+		"n": Null,
+		"a": Array{NumberInt(2)},
+		"r": Raw(`{"x": 1}`),
+	})
+	want := map[string]interface{}{
+		"s": "x",
+		"i": int64(1),
+		"f": 1.5,
+		"b": true,
+		"n": nil,
+		"a": []interface{}{int64(2)},
+		"r": Raw(`{"x": 1}`),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := Object{
+		"a": Array{NumberInt(1), Object{"b": Null}},
+		"c": String("x"),
+		"d": Object{"e": Bool(true)},
+		"f": Raw(`{"g": [1]}`),
+	}
+	got := []string{}
+	pre := func(n Node) error {
+		got = append(got, "pre "+n.Path.String()+" "+strconv.Itoa(n.Depth))
+		if n.Path.String() == "/d" {
+			return SkipChildren
+		}
+		return nil
+	}
+	post := func(n Node) error {
+		got = append(got, "post "+n.Path.String())
+		return nil
+	}
+	if err := Walk(root, pre, post); err != nil {
+		t.Fatalf("failed to walk %#v", err)
+	}
+	want := []string{
+		"pre  0",
+		"pre /a 1",
+		"pre /a/0 2",
+		"post /a/0",
+		"pre /a/1 2",
+		"pre /a/1/b 3",
+		"post /a/1/b",
+		"post /a/1",
+		"post /a",
+		"pre /c 1",
+		"post /c",
+		"pre /d 1",
+		"post /d",
+		"pre /f 1",
+		"post /f",
+		"post ",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestWalkSkipChildrenInPost(t *testing.T) {
+	root := Array{NumberInt(1), Array{NumberInt(2)}, NumberInt(3)}
+	visited := []string{}
+	post := func(n Node) error {
+		visited = append(visited, n.Path.String())
+		return SkipChildren
+	}
+	if err := Walk(root, nil, post); err != nil {
+		t.Fatalf("want nil, but got %v", err)
+	}
+	want := []string{"/0", "/1/0", "/1", "/2", ""}
+	if diff := cmp.Diff(visited, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestWalkSkipChildrenPairsPost(t *testing.T) {
+	root := Array{Array{NumberInt(1)}, Object{"a": Null}}
+	depth := 0
+	pre := func(n Node) error {
+		depth++
+		if n.Depth == 1 {
+			return SkipChildren
+		}
+		return nil
+	}
+	post := func(n Node) error {
+		depth--
+		return nil
+	}
+	if err := Walk(root, pre, post); err != nil {
+		t.Fatalf("failed to walk %#v", err)
+	}
+	if depth != 0 {
+		t.Fatalf("want pre and post to pair up, but got %d unmatched", depth)
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	root := Array{NumberInt(1), NumberInt(2), NumberInt(3)}
+	visited := 0
+	err := Walk(root, func(n Node) error {
+		if n.Value == NumberInt(2) {
+			if _, ok := n.Parent.(Array); !ok {
+				t.Errorf("want array parent, but got %v", n.Parent)
+			}
+			return StopWalk
+		}
+		visited++
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to walk %#v", err)
+	}
+	if visited != 2 {
+		t.Errorf("want 2 visited, but got %d", visited)
+	}
+
+	errSome := errors.New("some error")
+	if err := Walk(root, nil, func(n Node) error { return errSome }); !errors.Is(err, errSome) {
+		t.Errorf("want errSome, but got %v", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	root := Object{
+		"password": String("secret"),
+		"items":    Array{NumberInt(1), Null, NumberInt(2)},
+		"keep":     Object{"password": String("raw")},
+	}
+	got, err := Transform(root, func(n Node) (interface{}, error) {
+		switch {
+		case n.Path.String() == "/keep":
+			return n.Value, SkipChildren
+		case len(n.Path) > 0 && n.Path[len(n.Path)-1] == "password":
+			return String("***"), nil
+		case IsNull(n.Value):
+			return nil, DeleteNode
+		case KindOf(n.Value) == KindNumber:
+			return NumberInt(n.Value.(NumberInt) * 10), nil
+		}
+		return n.Value, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to transform %#v", err)
+	}
+	want := Object{
+		"password": String("***"),
+		"items":    Array{NumberInt(10), NumberInt(20)},
+		"keep":     Object{"password": String("raw")},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+	// 元の値は変更されない
+	if root["password"] != String("secret") || len(root["items"].(Array)) != 3 {
+		t.Errorf("original was modified: %v", root)
+	}
+}
+
+func TestTransformStop(t *testing.T) {
+	root := Array{NumberInt(1), NumberInt(2), NumberInt(3)}
+	got, err := Transform(root, func(n Node) (interface{}, error) {
+		if n.Value == NumberInt(2) {
+			return String("two"), StopWalk
+		}
+		if v, ok := n.Value.(NumberInt); ok {
+			return v + 1, nil
+		}
+		return n.Value, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to transform %#v", err)
+	}
+	want := Array{NumberInt(2), String("two"), NumberInt(3)}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
+
+func TestTransformNilKeepsValue(t *testing.T) {
+	root := Object{"a": Array{NumberInt(1)}, "b": String("x"), "c": NumberInt(3)}
+	got, err := Transform(root, func(n Node) (interface{}, error) {
+		switch n.Path.String() {
+		case "/a":
+			return nil, SkipChildren
+		case "/b":
+			return nil, StopWalk
+		}
+		return n.Value, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to transform %#v", err)
+	}
+	if diff := cmp.Diff(got, root); diff != "" {
+		t.Fatalf("got differs: (-got +want)\n%s", diff)
+	}
+}
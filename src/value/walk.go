@@ -0,0 +1,158 @@
+package value
+
+import (
+	"errors"
+
+	"github.com/sam8helloworld/json-go/pointer"
+)
+
+var (
+	// SkipChildren をWalkFuncやTransformFuncが返すと子を走査しない
+	SkipChildren = errors.New("skip children")
+	// StopWalk をWalkFuncやTransformFuncが返すと走査を終了する
+	StopWalk = errors.New("stop walk")
+	// DeleteNode をTransformFuncが返すと値を親から取り除く
+	DeleteNode = errors.New("delete node")
+)
+
+// Node は走査中の値とその位置を表す
+type Node struct {
+	Value interface{}
+	Path  pointer.Pointer
+	// Depth はルートを0とした深さ
+	Depth int
+	// Parent は親の配列かobject、ルートではnil
+	Parent interface{}
+}
+
+type WalkFunc func(n Node) error
+
+// Walk はrootを深さ優先で走査する
+// preは子を走査する前、postは子を走査した後に呼ばれ、どちらもnilにできる
+// objectの子はキーの昇順に走査する
+// preがSkipChildrenを返した場合も、子を走査せずにその値のpostを呼ぶ
+// postがSkipChildrenを返しても走査を続ける
+// StopWalkで終了した場合はnilを、それ以外のエラーで終了した場合はそのエラーを返す
+func Walk(root interface{}, pre, post WalkFunc) error {
+	err := walk(Node{Value: root, Path: pointer.Pointer{}}, pre, post)
+	if errors.Is(err, StopWalk) {
+		return nil
+	}
+	return err
+}
+
+func walk(n Node, pre, post WalkFunc) error {
+	if pre != nil {
+		err := pre(n)
+		if errors.Is(err, SkipChildren) {
+			return walkPost(n, post)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	switch val := n.Value.(type) {
+	case Raw:
+		// Rawはパースしていないので子を持たない値として扱う
+	case Array:
+		for i, vi := range val {
+			if err := walk(Node{Value: vi, Path: n.Path.AppendIndex(i), Depth: n.Depth + 1, Parent: val}, pre, post); err != nil {
+				return err
+			}
+		}
+	case Object:
+		for _, k := range SortedKeys(val) {
+			if err := walk(Node{Value: val[k], Path: n.Path.Append(k), Depth: n.Depth + 1, Parent: val}, pre, post); err != nil {
+				return err
+			}
+		}
+	}
+	return walkPost(n, post)
+}
+
+func walkPost(n Node, post WalkFunc) error {
+	if post == nil {
+		return nil
+	}
+	// 子は走査し終えているので、postのSkipChildrenは何もしない
+	if err := post(n); !errors.Is(err, SkipChildren) {
+		return err
+	}
+	return nil
+}
+
+// TransformFunc は値を置き換える
+// 置き換えない場合はn.Valueをそのまま返す
+type TransformFunc func(n Node) (interface{}, error)
+
+// Transform はrootを深さ優先で走査し、fnが返した値で置き換えた新しい値を返す
+// fnは子を走査する前に呼ばれ、置き換えた後の値の子を走査する
+// fnがSkipChildrenを返すと置き換えた値の子は走査せず、DeleteNodeを返すと親から取り除く
+// StopWalkかSkipChildrenと一緒にnilを返した場合は値を置き換えない
+// ルートを取り除いた場合はNullを返す
+// rootは変更されない
+func Transform(root interface{}, fn TransformFunc) (interface{}, error) {
+	v, deleted, err := transform(Node{Value: root, Path: pointer.Pointer{}}, fn)
+	if err != nil && !errors.Is(err, StopWalk) {
+		return nil, err
+	}
+	if deleted {
+		return Null, nil
+	}
+	return v, nil
+}
+
+func transform(n Node, fn TransformFunc) (interface{}, bool, error) {
+	v, err := fn(n)
+	if v == nil && (errors.Is(err, StopWalk) || errors.Is(err, SkipChildren)) {
+		v = n.Value
+	}
+	switch {
+	case errors.Is(err, DeleteNode):
+		return nil, true, nil
+	case errors.Is(err, SkipChildren):
+		return DeepCopy(v), false, nil
+	case err != nil:
+		// StopWalkの場合も残りは置き換えずに複製する
+		return DeepCopy(v), false, err
+	}
+
+	switch val := v.(type) {
+	case Array:
+		array := make(Array, 0, len(val))
+		for i, vi := range val {
+			nv, deleted, err := transform(Node{Value: vi, Path: n.Path.AppendIndex(i), Depth: n.Depth + 1, Parent: val}, fn)
+			if !deleted {
+				array = append(array, nv)
+			}
+			if err != nil {
+				if errors.Is(err, StopWalk) {
+					for _, rest := range val[i+1:] {
+						array = append(array, DeepCopy(rest))
+					}
+				}
+				return array, false, err
+			}
+		}
+		return array, false, nil
+	case Object:
+		object := make(Object, len(val))
+		keys := SortedKeys(val)
+		for i, k := range keys {
+			nv, deleted, err := transform(Node{Value: val[k], Path: n.Path.Append(k), Depth: n.Depth + 1, Parent: val}, fn)
+			if !deleted {
+				object[k] = nv
+			}
+			if err != nil {
+				if errors.Is(err, StopWalk) {
+					for _, rest := range keys[i+1:] {
+						object[rest] = DeepCopy(val[rest])
+					}
+				}
+				return object, false, err
+			}
+		}
+		return object, false, nil
+	}
+	return v, false, nil
+}